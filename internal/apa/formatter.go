@@ -3,43 +3,94 @@ package apa
 import (
 	"fmt"
 	"regexp"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/knhn1004/bibtext-to-apa6/internal/bibtex"
+	"github.com/knhn1004/bibtext-to-apa6/internal/doi"
+	"github.com/knhn1004/bibtext-to-apa6/internal/translit"
 )
 
 func Format(entry *bibtex.Entry) (string, error) {
+	return FormatLocale(entry, English)
+}
+
+// FormatLocale renders an entry using the terms of the given locale.
+func FormatLocale(entry *bibtex.Entry, loc *Locale) (string, error) {
+	if loc == nil {
+		loc = English
+	}
+
+	// Names in Cyrillic or Greek script are cited in Latin script
+	entry = translit.RomanizeNames(entry)
+
 	switch entry.Type {
 	case "article":
-		return formatArticle(entry), nil
+		return formatArticle(entry, loc), nil
 	case "book":
-		return formatBook(entry), nil
+		return formatBook(entry, loc), nil
 	case "inproceedings", "conference":
-		return formatInProceedings(entry), nil
+		return formatInProceedings(entry, loc), nil
 	case "inbook", "incollection":
-		return formatInBook(entry), nil
-	case "misc", "online":
-		return formatMisc(entry), nil
+		return formatInBook(entry, loc), nil
+	case "misc", "online", "video", "software":
+		return formatMisc(entry, loc), nil
 	case "phdthesis", "mastersthesis":
-		return formatThesis(entry), nil
+		return formatThesis(entry, loc), nil
 	default:
-		return formatGeneric(entry), nil
+		return formatGeneric(entry, loc), nil
 	}
 }
 
-func formatArticle(entry *bibtex.Entry) string {
-	authors := formatAuthors(entry.GetField("author"))
-	year := formatYear(entry.GetField("year"))
-	title := sentenceCase(entry.GetField("title"))
+// FormatArchived renders an entry citing archiveURL, an archived copy of the
+// page, in place of its live URL. An archived copy does not change, so no
+// retrieval date is given. Entries with a DOI are cited by their DOI as usual.
+func FormatArchived(entry *bibtex.Entry, archiveURL string, loc *Locale) (string, error) {
+	if archiveURL == "" {
+		return FormatLocale(entry, loc)
+	}
+
+	archived := &bibtex.Entry{
+		Type:   entry.Type,
+		Key:    entry.Key,
+		Fields: make(map[string]string, len(entry.Fields)),
+	}
+	for field, value := range entry.Fields {
+		archived.Fields[field] = value
+	}
+	archived.Fields["url"] = archiveURL
+	delete(archived.Fields, "urldate")
+
+	return FormatLocale(archived, loc)
+}
+
+func formatArticle(entry *bibtex.Entry, loc *Locale) string {
+	authors := formatAuthors(entry.GetField("author"), loc)
+	year := formatYear(entry, loc)
+	switch entry.GetField("entrysubtype") {
+	case "newspaper", "magazine":
+		year = formatDate(entry, loc)
+	}
+	title := withTranslation(sentenceCase(entry.GetField("title")), entry)
 	journal := entry.GetField("journal")
 	volume := entry.GetField("volume")
 	number := entry.GetField("number")
 	pages := formatPages(entry.GetField("pages"))
-	doi := entry.GetField("doi")
-
-	result := fmt.Sprintf("%s (%s). %s.", authors, year, title)
+	articleNumber := formatArticleNumber(entry)
+	doiStr := entry.GetField("doi")
+	state := publicationState(entry)
+
+	result := fmt.Sprintf("%s (%s). %s", authors, year, endSentence(title))
+	if entry.GetField("author") == "" {
+		// Unsigned articles, common in newspapers, are cited by title
+		result = fmt.Sprintf("%s (%s).", endSentence(title), year)
+	}
 
-	if journal != "" {
+	if journal != "" && state == stateInPress {
+		// Volume, issue and pages are not known until the article is published
+		result += fmt.Sprintf(" %s.", italicize(journal))
+	} else if journal != "" {
 		result += fmt.Sprintf(" %s", italicize(journal))
 		if volume != "" {
 			result += fmt.Sprintf(", %s", italicize(volume))
@@ -47,27 +98,45 @@ func formatArticle(entry *bibtex.Entry) string {
 				result += fmt.Sprintf("(%s)", number)
 			}
 		}
-		if pages != "" {
+		if articleNumber != "" {
+			result += fmt.Sprintf(", %s %s", loc.Article, articleNumber)
+		} else if pages != "" {
 			result += fmt.Sprintf(", %s", pages)
 		}
 		result += "."
 	}
 
-	if doi != "" {
-		result += fmt.Sprintf(" https://doi.org/%s", doi)
+	if state == stateAdvanceOnline {
+		result += fmt.Sprintf(" %s.", loc.AdvanceOnline)
+	}
+
+	if d := doi.Normalize(doiStr); d != "" {
+		result += fmt.Sprintf(" %s", doi.URL(d))
+	} else if strings.HasPrefix(doiStr, "http://") || strings.HasPrefix(doiStr, "https://") {
+		// A link that is not a DOI, such as a publisher page, is given as
+		// it is; anything else in the field cannot be resolved and is left
+		// out
+		result += fmt.Sprintf(" %s", doiStr)
+	} else if url := entry.GetField("url"); url != "" {
+		// Online articles without a DOI, such as newspaper articles
+		result += fmt.Sprintf(" %s", fmt.Sprintf(loc.Retrieved, url))
 	}
 
 	return result
 }
 
-func formatBook(entry *bibtex.Entry) string {
-	authors := formatAuthors(entry.GetField("author"))
-	year := formatYear(entry.GetField("year"))
-	title := italicize(sentenceCase(entry.GetField("title")))
+func formatBook(entry *bibtex.Entry, loc *Locale) string {
+	creator := formatCreator(entry, loc)
+	year := formatYear(entry, loc)
+	title := withTranslation(italicize(sentenceCase(entry.GetField("title"))), entry)
 	publisher := entry.GetField("publisher")
 	address := entry.GetField("address")
 
-	result := fmt.Sprintf("%s (%s). %s", authors, year, title)
+	result := fmt.Sprintf("%s (%s). %s", creator, year, title)
+
+	if details := bookDetails(entry, loc); details != "" {
+		result += fmt.Sprintf(" (%s)", details)
+	}
 
 	if address != "" && publisher != "" {
 		result += fmt.Sprintf(". %s: %s", address, publisher)
@@ -76,21 +145,26 @@ func formatBook(entry *bibtex.Entry) string {
 	}
 
 	result += "."
+
+	if origYear := originalYear(entry); origYear != "" {
+		result += fmt.Sprintf(" (%s)", fmt.Sprintf(loc.OriginalWork, origYear))
+	}
+
 	return result
 }
 
-func formatInProceedings(entry *bibtex.Entry) string {
-	authors := formatAuthors(entry.GetField("author"))
-	year := formatYear(entry.GetField("year"))
-	title := sentenceCase(entry.GetField("title"))
+func formatInProceedings(entry *bibtex.Entry, loc *Locale) string {
+	authors := formatAuthors(entry.GetField("author"), loc)
+	year := formatYear(entry, loc)
+	title := withTranslation(sentenceCase(entry.GetField("title")), entry)
 	booktitle := italicize(entry.GetField("booktitle"))
 	pages := formatPages(entry.GetField("pages"))
 	publisher := entry.GetField("publisher")
 
-	result := fmt.Sprintf("%s (%s). %s. In %s", authors, year, title, booktitle)
+	result := fmt.Sprintf("%s (%s). %s. %s %s", authors, year, title, loc.In, booktitle)
 
 	if pages != "" {
-		result += fmt.Sprintf(" (pp. %s)", pages)
+		result += fmt.Sprintf(" (%s %s)", loc.Pages, pages)
 	}
 
 	if publisher != "" {
@@ -101,25 +175,44 @@ func formatInProceedings(entry *bibtex.Entry) string {
 	return result
 }
 
-func formatInBook(entry *bibtex.Entry) string {
-	authors := formatAuthors(entry.GetField("author"))
-	year := formatYear(entry.GetField("year"))
-	title := sentenceCase(entry.GetField("title"))
+func formatInBook(entry *bibtex.Entry, loc *Locale) string {
+	authors := formatAuthors(entry.GetField("author"), loc)
+	year := formatYear(entry, loc)
+	title := withTranslation(sentenceCase(entry.GetField("title")), entry)
 	booktitle := italicize(entry.GetField("booktitle"))
 	editor := entry.GetField("editor")
+	translator := entry.GetField("translator")
 	pages := formatPages(entry.GetField("pages"))
 	publisher := entry.GetField("publisher")
 
 	result := fmt.Sprintf("%s (%s). %s", authors, year, title)
 
+	contributors := []string{}
 	if editor != "" {
-		result += fmt.Sprintf(". In %s (Ed.), %s", formatAuthors(editor), booktitle)
+		contributors = append(contributors, fmt.Sprintf("%s (%s)", formatNamesDirect(editor, loc), loc.editorLabel(editor)))
+	}
+	if translator != "" {
+		contributors = append(contributors, fmt.Sprintf("%s (%s)", formatNamesDirect(translator, loc), loc.Translator))
+	}
+
+	if len(contributors) > 0 {
+		result += fmt.Sprintf(". %s %s, %s", loc.In, strings.Join(contributors, fmt.Sprintf(" %s ", loc.And)), booktitle)
 	} else {
-		result += fmt.Sprintf(". In %s", booktitle)
+		result += fmt.Sprintf(". %s %s", loc.In, booktitle)
 	}
 
+	details := []string{}
+	if edition := formatEdition(entry.GetField("edition"), loc); edition != "" {
+		details = append(details, edition)
+	}
+	if volume := entry.GetField("volume"); volume != "" {
+		details = append(details, fmt.Sprintf("%s %s", loc.Volume, volume))
+	}
 	if pages != "" {
-		result += fmt.Sprintf(" (pp. %s)", pages)
+		details = append(details, fmt.Sprintf("%s %s", loc.Pages, pages))
+	}
+	if len(details) > 0 {
+		result += fmt.Sprintf(" (%s)", strings.Join(details, ", "))
 	}
 
 	if publisher != "" {
@@ -127,18 +220,23 @@ func formatInBook(entry *bibtex.Entry) string {
 	}
 
 	result += "."
+
+	if origYear := originalYear(entry); origYear != "" {
+		result += fmt.Sprintf(" (%s)", fmt.Sprintf(loc.OriginalWork, origYear))
+	}
+
 	return result
 }
 
-func formatThesis(entry *bibtex.Entry) string {
-	authors := formatAuthors(entry.GetField("author"))
-	year := formatYear(entry.GetField("year"))
-	title := italicize(sentenceCase(entry.GetField("title")))
+func formatThesis(entry *bibtex.Entry, loc *Locale) string {
+	authors := formatAuthors(entry.GetField("author"), loc)
+	year := formatYear(entry, loc)
+	title := withTranslation(italicize(sentenceCase(entry.GetField("title"))), entry)
 	school := entry.GetField("school")
-	thesisType := "Doctoral dissertation"
+	thesisType := loc.DoctoralThesis
 
 	if entry.Type == "mastersthesis" {
-		thesisType = "Master's thesis"
+		thesisType = loc.MastersThesis
 	}
 
 	result := fmt.Sprintf("%s (%s). %s [%s]", authors, year, title, thesisType)
@@ -151,40 +249,201 @@ func formatThesis(entry *bibtex.Entry) string {
 	return result
 }
 
-func formatMisc(entry *bibtex.Entry) string {
-	authors := formatAuthors(entry.GetField("author"))
-	year := formatYear(entry.GetField("year"))
-	title := italicize(sentenceCase(entry.GetField("title")))
-	url := entry.GetField("url")
+// formatMisc renders web pages, videos, software and other works without a
+// container. Works with no author, editor or group author are cited by
+// title, which takes the author position.
+func formatMisc(entry *bibtex.Entry, loc *Locale) string {
+	switch entry.GetField("entrysubtype") {
+	case "wiki":
+		return formatWiki(entry, loc)
+	case "blog", "social":
+		return formatPost(entry, loc)
+	}
 
-	result := fmt.Sprintf("%s (%s). %s", authors, year, title)
+	year := formatDate(entry, loc)
+	title := withTranslation(italicize(sentenceCase(entry.GetField("title"))), entry)
+	if medium := mediumLabel(entry, loc); medium != "" {
+		title += fmt.Sprintf(" [%s]", medium)
+	}
+	organization := entry.GetField("organization")
 
-	if url != "" {
-		result += fmt.Sprintf(". Retrieved from %s", url)
-	} else {
-		result += "."
+	creator := webCreator(entry, loc)
+	if creator == "" {
+		return fmt.Sprintf("%s (%s).", endSentence(title), year) + webLocation(entry, loc)
 	}
 
-	return result
+	result := fmt.Sprintf("%s (%s). %s", creator, year, endSentence(title))
+
+	// The site name is only repeated when it is not already the author
+	if organization != "" && entry.GetField("author") != "" {
+		result += " " + endSentence(organization)
+	}
+
+	return result + webLocation(entry, loc)
 }
 
-func formatGeneric(entry *bibtex.Entry) string {
-	authors := formatAuthors(entry.GetField("author"))
-	year := formatYear(entry.GetField("year"))
+// formatPost renders a blog or social media post. Its title, or the text of
+// a social media post, is not italicized and is followed by the kind of
+// post, e.g. "Title of post [Web log post]".
+func formatPost(entry *bibtex.Entry, loc *Locale) string {
+	year := formatDate(entry, loc)
+
+	// Social media posts are quoted as written
+	text := strings.TrimSpace(entry.GetField("title"))
+	label := loc.postLabel(entry.GetField("organization"))
+	if entry.GetField("entrysubtype") == "blog" {
+		text = withTranslation(sentenceCase(text), entry)
+		label = loc.BlogPost
+	}
+	post := fmt.Sprintf("%s [%s].", text, label)
+
+	// A post is attributed to its author, or the blog or account
+	creator := webCreator(entry, loc)
+	if entry.GetField("entrysubtype") == "social" && entry.GetField("author") == "" {
+		creator = ""
+		if account := entry.GetField("nameaddon"); account != "" {
+			creator = account + "."
+		}
+	}
+
+	if creator == "" {
+		return fmt.Sprintf("%s (%s).", post, year) + webLocation(entry, loc)
+	}
+	return fmt.Sprintf("%s (%s). %s", creator, year, post) + webLocation(entry, loc)
+}
+
+// formatWiki renders an entry in a wiki, which has no author: the title
+// takes the author position and the wiki is given as the container, e.g.
+// "Title. (n.d.). In *Wikipedia*. Retrieved March 3, 2021, from URL".
+func formatWiki(entry *bibtex.Entry, loc *Locale) string {
 	title := sentenceCase(entry.GetField("title"))
+	year := formatYear(entry, loc)
+	wiki := entry.GetField("organization")
+	if wiki == "" {
+		wiki = "Wikipedia"
+	}
+
+	result := fmt.Sprintf("%s (%s). %s %s.", endSentence(title), year, loc.In, italicize(wiki))
+
+	return result + webLocation(entry, loc)
+}
+
+// webCreator returns the author of an online work, with the screen name of
+// an account in brackets after it, as in "Obama, B. [BarackObama].". It
+// returns "" when the work has no author, editor or group author.
+func webCreator(entry *bibtex.Entry, loc *Locale) string {
+	hasCreator := false
+	for _, field := range []string{"author", "editor", "organization", "institution"} {
+		if entry.GetField(field) != "" {
+			hasCreator = true
+		}
+	}
+	if !hasCreator {
+		return ""
+	}
+
+	creator := formatCreator(entry, loc)
+
+	addon := entry.GetField("nameaddon")
+	if addon == "" || entry.GetField("author") == "" {
+		return creator
+	}
+
+	// Keep the period of a final initial, but not one added after a name
+	if !finalInitial.MatchString(creator) {
+		creator = strings.TrimSuffix(creator, ".")
+	}
+	return fmt.Sprintf("%s [%s].", creator, addon)
+}
+
+// finalInitial matches a name list ending in an initial, such as "Doe, J.".
+var finalInitial = regexp.MustCompile(`(?:^|[\s,.\-])\p{Lu}\.$`)
+
+// webLocation returns the DOI or retrieval statement that ends an online
+// reference, with a leading space. A retrieval date is only given for
+// content that is meant to change, such as wikis and undated pages.
+func webLocation(entry *bibtex.Entry, loc *Locale) string {
+	if d := doi.Normalize(entry.GetField("doi")); d != "" {
+		return " " + doi.URL(d)
+	}
+
+	url := entry.GetField("url")
+	if url == "" {
+		return ""
+	}
+
+	var accessed time.Time
+	if entry.GetField("entrysubtype") == "wiki" || entry.GetField("year") == "" {
+		accessed = parseURLDate(entry.GetField("urldate"))
+	}
+
+	return " " + loc.RetrievedFrom(accessed, url)
+}
+
+// endSentence ends text with a period unless it already ends with a period,
+// question mark or exclamation mark, so that titles such as "Why?" are not
+// followed by another period.
+func endSentence(text string) string {
+	text = strings.TrimSpace(text)
+	trimmed := strings.TrimRight(text, "*")
+	if strings.HasSuffix(trimmed, ".") || strings.HasSuffix(trimmed, "?") || strings.HasSuffix(trimmed, "!") {
+		return text
+	}
+	return text + "."
+}
+
+// mediumLabel returns the bracketed description that follows the title of
+// non-print works, such as "Video file" or "Computer software".
+func mediumLabel(entry *bibtex.Entry, loc *Locale) string {
+	switch entry.Type {
+	case "video":
+		return loc.VideoFile
+	case "software":
+		return loc.Software
+	}
+	return ""
+}
+
+// parseURLDate parses a biblatex urldate (YYYY-MM-DD), returning the zero
+// time if the field is missing or malformed.
+func parseURLDate(value string) time.Time {
+	t, err := time.Parse("2006-01-02", strings.TrimSpace(value))
+	if err != nil {
+		return time.Time{}
+	}
+	return t
+}
+
+func formatGeneric(entry *bibtex.Entry, loc *Locale) string {
+	authors := formatAuthors(entry.GetField("author"), loc)
+	year := formatYear(entry, loc)
+	title := withTranslation(sentenceCase(entry.GetField("title")), entry)
 
 	return fmt.Sprintf("%s (%s). %s.", authors, year, title)
 }
 
-func formatAuthors(authors string) string {
+func formatAuthors(authors string, loc *Locale) string {
 	if authors == "" {
-		return "Unknown"
+		return loc.NoAuthor
 	}
 
 	// Fix encoding issues that might have survived the parser
 	authors = fixAuthorEncoding(authors)
 
-	authorList := strings.Split(authors, " and ")
+	// Names that end in initials already end in a period; organization and
+	// single-word names need one before the date
+	result := joinAuthors(authors, loc)
+	if !strings.HasSuffix(result, ".") {
+		result += "."
+	}
+	return result
+}
+
+// joinAuthors formats each author and joins them with commas and "&",
+// eliding all but the last author after the sixth when there are more than
+// seven.
+func joinAuthors(authors string, loc *Locale) string {
+	authorList := bibtex.SplitNames(authors)
 	formatted := []string{}
 
 	for i, author := range authorList {
@@ -192,59 +451,20 @@ func formatAuthors(authors string) string {
 		if len(authorList) > 7 && i == 6 {
 			formatted = append(formatted, "…")
 			// Now process the last author
-			lastAuthor := authorList[len(authorList)-1]
-			lastAuthor = strings.TrimSpace(lastAuthor)
-			parts := strings.Split(lastAuthor, ",")
-
-			if len(parts) == 2 {
-				lastName := strings.TrimSpace(parts[0])
-				firstName := strings.TrimSpace(parts[1])
-				initials := getInitials(firstName)
-				formatted = append(formatted, fmt.Sprintf("%s, %s", lastName, initials))
-			} else {
-				words := strings.Fields(lastAuthor)
-				if len(words) > 1 {
-					lastName := words[len(words)-1]
-					firstName := strings.Join(words[:len(words)-1], " ")
-					initials := getInitials(firstName)
-					formatted = append(formatted, fmt.Sprintf("%s, %s", lastName, initials))
-				} else {
-					formatted = append(formatted, lastAuthor)
-				}
-			}
+			formatted = append(formatted, formatAuthorName(authorList[len(authorList)-1]))
 			break // Exit loop after processing last author
 		} else if len(authorList) > 7 && i > 6 {
 			continue // Skip all authors after the 6th except the last (already handled)
 		}
 
 		// Process regular authors (first 6 when >7 authors, or all when <=7)
-		author = strings.TrimSpace(author)
-		parts := strings.Split(author, ",")
-
-		if len(parts) == 2 {
-			lastName := strings.TrimSpace(parts[0])
-			firstName := strings.TrimSpace(parts[1])
-			initials := getInitials(firstName)
-
-			formatted = append(formatted, fmt.Sprintf("%s, %s", lastName, initials))
-		} else {
-			words := strings.Fields(author)
-			if len(words) > 1 {
-				lastName := words[len(words)-1]
-				firstName := strings.Join(words[:len(words)-1], " ")
-				initials := getInitials(firstName)
-
-				formatted = append(formatted, fmt.Sprintf("%s, %s", lastName, initials))
-			} else {
-				formatted = append(formatted, author)
-			}
-		}
+		formatted = append(formatted, formatAuthorName(author))
 	}
 
 	if len(formatted) == 1 {
 		return formatted[0]
 	} else if len(formatted) == 2 {
-		return strings.Join(formatted, ", & ")
+		return strings.Join(formatted, loc.joinLast())
 	} else {
 		// Check if we have ellipsis
 		hasEllipsis := false
@@ -275,11 +495,180 @@ func formatAuthors(authors string) string {
 			// Normal case without ellipsis
 			lastAuthor := formatted[len(formatted)-1]
 			otherAuthors := strings.Join(formatted[:len(formatted)-1], ", ")
-			return fmt.Sprintf("%s, & %s", otherAuthors, lastAuthor)
+			return otherAuthors + loc.joinLast() + lastAuthor
 		}
 	}
 }
 
+// withTranslation appends the English translation of a non-English title in
+// square brackets, e.g. "La psychologie de l'enfant [The psychology of the child]".
+func withTranslation(title string, entry *bibtex.Entry) string {
+	translated := entry.TranslatedTitle()
+	if translated == "" {
+		return title
+	}
+	return fmt.Sprintf("%s [%s]", title, sentenceCase(translated))
+}
+
+// formatCreator returns the authors of an entry, or its editors followed by
+// the "(Ed.)"/"(Eds.)" role when the work has no authors, or the group author.
+func formatCreator(entry *bibtex.Entry, loc *Locale) string {
+	author := entry.GetField("author")
+	editor := entry.GetField("editor")
+
+	if author == "" && editor != "" {
+		return fmt.Sprintf("%s (%s).", joinAuthors(fixAuthorEncoding(editor), loc), loc.editorLabel(editor))
+	}
+
+	// Group authors such as the organization behind a web page
+	if author == "" {
+		for _, field := range []string{"organization", "institution"} {
+			if group := entry.GetField(field); group != "" {
+				return strings.TrimSuffix(group, ".") + "."
+			}
+		}
+	}
+
+	return formatAuthors(author, loc)
+}
+
+// bookDetails builds the parenthetical that follows a book title, e.g.
+// "2nd ed., Vol. 3; J. Smith, Ed.; A. Jones, Trans."
+func bookDetails(entry *bibtex.Entry, loc *Locale) string {
+	groups := []string{}
+
+	edition := []string{}
+	if ed := formatEdition(entry.GetField("edition"), loc); ed != "" {
+		edition = append(edition, ed)
+	}
+	if series := entry.GetField("series"); series != "" {
+		edition = append(edition, series)
+	}
+	if volume := entry.GetField("volume"); volume != "" {
+		edition = append(edition, fmt.Sprintf("%s %s", loc.Volume, volume))
+	}
+	if len(edition) > 0 {
+		groups = append(groups, strings.Join(edition, ", "))
+	}
+
+	// Editors of an authored book are listed after the title; editor-only
+	// books already name them in the author position.
+	if editor := entry.GetField("editor"); editor != "" && entry.GetField("author") != "" {
+		groups = append(groups, fmt.Sprintf("%s, %s", formatNamesDirect(editor, loc), loc.editorLabel(editor)))
+	}
+
+	if translator := entry.GetField("translator"); translator != "" {
+		groups = append(groups, fmt.Sprintf("%s, %s", formatNamesDirect(translator, loc), loc.Translator))
+	}
+
+	return strings.Join(groups, "; ")
+}
+
+// formatEdition turns an edition field ("2", "second", "2nd", "Rev.") into
+// its APA form ("2nd ed.", "Rev. ed."). The first edition is omitted.
+func formatEdition(edition string, loc *Locale) string {
+	edition = strings.TrimSpace(edition)
+	edition = strings.TrimSuffix(strings.TrimSuffix(edition, " edition"), " ed.")
+	if edition == "" {
+		return ""
+	}
+
+	words := map[string]int{
+		"first": 1, "second": 2, "third": 3, "fourth": 4, "fifth": 5,
+		"sixth": 6, "seventh": 7, "eighth": 8, "ninth": 9, "tenth": 10,
+	}
+
+	n := 0
+	if v, ok := words[strings.ToLower(edition)]; ok {
+		n = v
+	} else if v, err := strconv.Atoi(strings.TrimRight(edition, "stndrh")); err == nil {
+		n = v
+	}
+
+	if n == 1 {
+		return ""
+	}
+	if n > 1 {
+		return fmt.Sprintf("%s %s", loc.ordinal(n), loc.Edition)
+	}
+
+	return fmt.Sprintf("%s %s", edition, loc.Edition)
+}
+
+func ordinal(n int) string {
+	suffix := "th"
+	if n%100 < 11 || n%100 > 13 {
+		switch n % 10 {
+		case 1:
+			suffix = "st"
+		case 2:
+			suffix = "nd"
+		case 3:
+			suffix = "rd"
+		}
+	}
+	return fmt.Sprintf("%d%s", n, suffix)
+}
+
+// originalYear returns the year of first publication for reprints and
+// translations.
+func originalYear(entry *bibtex.Entry) string {
+	if year := entry.GetField("origyear"); year != "" {
+		return year
+	}
+	if date := entry.GetField("origdate"); len(date) >= 4 {
+		return date[:4]
+	}
+	return ""
+}
+
+// formatAuthorName inverts a name for the author position, e.g.
+// "Smith, J. A.", leaving organization names as written.
+func formatAuthorName(author string) string {
+	name := bibtex.ParseName(author)
+	if name.Corporate || name.Given == "" {
+		return name.Family
+	}
+
+	result := fmt.Sprintf("%s, %s", name.Family, getInitials(name.Given))
+	if name.Suffix != "" {
+		result += ", " + name.Suffix
+	}
+	return result
+}
+
+// formatNamesDirect formats names with initials first ("J. Smith & K. Doe"),
+// as APA requires for editors and translators outside the author position.
+func formatNamesDirect(names string, loc *Locale) string {
+	names = fixAuthorEncoding(names)
+
+	formatted := []string{}
+	for _, name := range bibtex.SplitNames(names) {
+		lastName, firstName := splitName(name)
+		if firstName == "" {
+			formatted = append(formatted, lastName)
+			continue
+		}
+		formatted = append(formatted, fmt.Sprintf("%s %s", getInitials(firstName), lastName))
+	}
+
+	switch len(formatted) {
+	case 1:
+		return formatted[0]
+	case 2:
+		return strings.Join(formatted, fmt.Sprintf(" %s ", loc.And))
+	default:
+		return strings.Join(formatted[:len(formatted)-1], ", ") + loc.joinLast() + formatted[len(formatted)-1]
+	}
+}
+
+// splitName splits a BibTeX name ("Last, First" or "First Last") into its
+// last and first name parts.
+func splitName(name string) (string, string) {
+	parsed := bibtex.ParseName(name)
+	return parsed.Family, parsed.Given
+}
+
 func getInitials(firstName string) string {
 	parts := strings.Fields(firstName)
 	initials := []string{}
@@ -287,7 +676,7 @@ func getInitials(firstName string) string {
 	for _, part := range parts {
 		if len(part) > 0 {
 			// Just the initial without period after it
-			initials = append(initials, string(part[0]))
+			initials = append(initials, string([]rune(part)[0]))
 		}
 	}
 
@@ -295,13 +684,95 @@ func getInitials(firstName string) string {
 	return strings.Join(initials, "") + "."
 }
 
-func formatYear(year string) string {
+func formatYear(entry *bibtex.Entry, loc *Locale) string {
+	if publicationState(entry) == stateInPress {
+		return loc.InPress
+	}
+
+	year := entry.GetField("year")
 	if year == "" {
-		return "n.d."
+		return loc.NoDate
 	}
 	return year
 }
 
+// formatDate returns the year followed by the month and day when the entry
+// has them, e.g. "2021, March 3", as used for web pages and newspaper
+// articles.
+func formatDate(entry *bibtex.Entry, loc *Locale) string {
+	year := formatYear(entry, loc)
+	month := parseMonth(entry.GetField("month"))
+	if month == 0 || entry.GetField("year") == "" || publicationState(entry) == stateInPress {
+		return year
+	}
+
+	day, err := strconv.Atoi(entry.GetField("day"))
+	if err != nil || day < 1 || day > 31 {
+		day = 0
+	}
+
+	return fmt.Sprintf("%s, %s", year, loc.formatMonthDay(month, day))
+}
+
+var monthAbbreviations = []string{"jan", "feb", "mar", "apr", "may", "jun", "jul", "aug", "sep", "oct", "nov", "dec"}
+
+// parseMonth reads a BibTeX month given as a number ("3"), a macro ("mar")
+// or an English name ("March"), returning 0 if it is not recognized.
+func parseMonth(value string) int {
+	value = strings.ToLower(strings.TrimSpace(value))
+	if n, err := strconv.Atoi(value); err == nil {
+		if n >= 1 && n <= 12 {
+			return n
+		}
+		return 0
+	}
+
+	if len(value) >= 3 {
+		for i, abbr := range monthAbbreviations {
+			if value[:3] == abbr {
+				return i + 1
+			}
+		}
+	}
+
+	return 0
+}
+
+type pubState int
+
+const (
+	statePublished pubState = iota
+	stateInPress
+	stateAdvanceOnline
+)
+
+// publicationState reads the biblatex pubstate field, treating accepted and
+// forthcoming papers as in press.
+func publicationState(entry *bibtex.Entry) pubState {
+	state := strings.ToLower(entry.GetField("pubstate"))
+	state = strings.NewReplacer(" ", "", "-", "", "_", "").Replace(state)
+
+	switch state {
+	case "inpress", "forthcoming", "accepted":
+		return stateInPress
+	case "prepublished", "aheadofprint", "advanceonline", "advanceonlinepublication", "onlinefirst", "earlyaccess", "earlyview":
+		return stateAdvanceOnline
+	}
+
+	return statePublished
+}
+
+// formatArticleNumber returns the e-locator or article number that journals
+// such as PLOS ONE use in place of page ranges.
+func formatArticleNumber(entry *bibtex.Entry) string {
+	for _, field := range []string{"eid", "articleno", "articlenumber"} {
+		if value := strings.TrimSpace(entry.GetField(field)); value != "" {
+			return strings.TrimSpace(strings.TrimPrefix(value, "Article"))
+		}
+	}
+	return ""
+}
+
 func formatPages(pages string) string {
 	if pages == "" {
 		return ""
@@ -0,0 +1,60 @@
+package apa
+
+import (
+	"flag"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/knhn1004/bibtext-to-apa6/internal/bibtex"
+)
+
+var update = flag.Bool("update", false, "rewrite the golden files in testdata")
+
+// TestFormatGolden formats each testdata/*.bib entry and compares the
+// citation with the matching .golden file. Run with -update to regenerate
+// them after an intended change, and review the diff.
+func TestFormatGolden(t *testing.T) {
+	inputs, err := filepath.Glob(filepath.Join("testdata", "*.bib"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(inputs) == 0 {
+		t.Fatal("no test entries in testdata")
+	}
+
+	for _, input := range inputs {
+		name := strings.TrimSuffix(filepath.Base(input), ".bib")
+		t.Run(name, func(t *testing.T) {
+			data, err := os.ReadFile(input)
+			if err != nil {
+				t.Fatal(err)
+			}
+			entry, err := bibtex.Parse(string(data))
+			if err != nil {
+				t.Fatalf("failed to parse %s: %v", input, err)
+			}
+
+			got, err := Format(entry)
+			if err != nil {
+				t.Fatalf("Format() error = %v", err)
+			}
+
+			golden := filepath.Join("testdata", name+".golden")
+			if *update {
+				if err := os.WriteFile(golden, []byte(got+"\n"), 0644); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			want, err := os.ReadFile(golden)
+			if err != nil {
+				t.Fatalf("failed to read golden file: %v", err)
+			}
+			if got != strings.TrimSuffix(string(want), "\n") {
+				t.Errorf("Format() =\n%s\nwant\n%s", got, want)
+			}
+		})
+	}
+}
@@ -0,0 +1,276 @@
+package apa
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/knhn1004/bibtext-to-apa6/internal/bibtex"
+)
+
+// Locale holds the translated terms used when rendering a reference.
+type Locale struct {
+	Code           string
+	And            string
+	SerialComma    bool // comma before the final "&" in name lists
+	NoDate         string
+	NoAuthor       string // in place of a missing author
+	Editor         string
+	Editors        string
+	Translator     string
+	Edition        string
+	Volume         string
+	Pages          string
+	In             string
+	Article        string
+	InPress        string
+	AdvanceOnline  string
+	Retrieved      string // "Retrieved from %s"
+	RetrievedOn    string // "Retrieved %s, from %s"
+	OriginalWork   string // "Original work published %s"
+	DoctoralThesis string
+	MastersThesis  string
+	VideoFile      string
+	Software       string
+	BlogPost       string
+	SocialPost     string // "%s post", given the platform
+	StatusUpdate   string // "%s status update", for Facebook
+	Months         [12]string
+	ordinal        func(n int) string
+	dateFormat     func(day int, month string, year int) string
+	monthDay       func(day int, month string) string
+}
+
+var English = &Locale{
+	Code:           "en",
+	And:            "&",
+	SerialComma:    true,
+	NoDate:         "n.d.",
+	NoAuthor:       "Unknown",
+	Editor:         "Ed.",
+	Editors:        "Eds.",
+	Translator:     "Trans.",
+	Edition:        "ed.",
+	Volume:         "Vol.",
+	Pages:          "pp.",
+	In:             "In",
+	Article:        "Article",
+	InPress:        "in press",
+	AdvanceOnline:  "Advance online publication",
+	Retrieved:      "Retrieved from %s",
+	RetrievedOn:    "Retrieved %s, from %s",
+	OriginalWork:   "Original work published %s",
+	DoctoralThesis: "Doctoral dissertation",
+	MastersThesis:  "Master's thesis",
+	VideoFile:      "Video file",
+	Software:       "Computer software",
+	BlogPost:       "Web log post",
+	SocialPost:     "%s post",
+	StatusUpdate:   "%s status update",
+	Months: [12]string{"January", "February", "March", "April", "May", "June",
+		"July", "August", "September", "October", "November", "December"},
+	ordinal: ordinal,
+	dateFormat: func(day int, month string, year int) string {
+		return fmt.Sprintf("%s %d, %d", month, day, year)
+	},
+	monthDay: func(day int, month string) string {
+		return fmt.Sprintf("%s %d", month, day)
+	},
+}
+
+var German = &Locale{
+	Code:           "de",
+	And:            "&",
+	SerialComma:    true,
+	NoDate:         "o. J.",
+	NoAuthor:       "Unbekannt",
+	Editor:         "Hrsg.",
+	Editors:        "Hrsg.",
+	Translator:     "Übers.",
+	Edition:        "Aufl.",
+	Volume:         "Bd.",
+	Pages:          "S.",
+	In:             "In",
+	Article:        "Artikel",
+	InPress:        "im Druck",
+	AdvanceOnline:  "Vorab-Onlinepublikation",
+	Retrieved:      "Abgerufen von %s",
+	RetrievedOn:    "Abgerufen am %s von %s",
+	OriginalWork:   "Originalarbeit erschienen %s",
+	DoctoralThesis: "Dissertation",
+	MastersThesis:  "Masterarbeit",
+	VideoFile:      "Video",
+	Software:       "Computer-Software",
+	BlogPost:       "Weblog-Beitrag",
+	SocialPost:     "%s-Beitrag",
+	StatusUpdate:   "%s-Statusmeldung",
+	Months: [12]string{"Januar", "Februar", "März", "April", "Mai", "Juni",
+		"Juli", "August", "September", "Oktober", "November", "Dezember"},
+	ordinal: func(n int) string {
+		return fmt.Sprintf("%d.", n)
+	},
+	dateFormat: func(day int, month string, year int) string {
+		return fmt.Sprintf("%d. %s %d", day, month, year)
+	},
+	monthDay: func(day int, month string) string {
+		return fmt.Sprintf("%d. %s", day, month)
+	},
+}
+
+var Spanish = &Locale{
+	Code:           "es",
+	And:            "y",
+	NoDate:         "s.f.",
+	NoAuthor:       "Desconocido",
+	Editor:         "Ed.",
+	Editors:        "Eds.",
+	Translator:     "Trad.",
+	Edition:        "ed.",
+	Volume:         "Vol.",
+	Pages:          "pp.",
+	In:             "En",
+	Article:        "Artículo",
+	InPress:        "en prensa",
+	AdvanceOnline:  "Publicación anticipada en línea",
+	Retrieved:      "Recuperado de %s",
+	RetrievedOn:    "Recuperado el %s de %s",
+	OriginalWork:   "Trabajo original publicado en %s",
+	DoctoralThesis: "Tesis doctoral",
+	MastersThesis:  "Tesis de maestría",
+	VideoFile:      "Archivo de video",
+	Software:       "Software",
+	BlogPost:       "Entrada de blog",
+	SocialPost:     "Publicación en %s",
+	StatusUpdate:   "Actualización de estado en %s",
+	Months: [12]string{"enero", "febrero", "marzo", "abril", "mayo", "junio",
+		"julio", "agosto", "septiembre", "octubre", "noviembre", "diciembre"},
+	ordinal: func(n int) string {
+		return fmt.Sprintf("%d.ª", n)
+	},
+	dateFormat: func(day int, month string, year int) string {
+		return fmt.Sprintf("%d de %s de %d", day, month, year)
+	},
+	monthDay: func(day int, month string) string {
+		return fmt.Sprintf("%d de %s", day, month)
+	},
+}
+
+var French = &Locale{
+	Code:           "fr",
+	And:            "et",
+	NoDate:         "s.d.",
+	NoAuthor:       "Inconnu",
+	Editor:         "Éd.",
+	Editors:        "Éds.",
+	Translator:     "Trad.",
+	Edition:        "éd.",
+	Volume:         "Vol.",
+	Pages:          "p.",
+	In:             "Dans",
+	Article:        "Article",
+	InPress:        "sous presse",
+	AdvanceOnline:  "Publication en ligne anticipée",
+	Retrieved:      "Repéré à %s",
+	RetrievedOn:    "Consulté le %s, à l'adresse %s",
+	OriginalWork:   "Œuvre originale publiée en %s",
+	DoctoralThesis: "Thèse de doctorat",
+	MastersThesis:  "Mémoire de master",
+	VideoFile:      "Fichier vidéo",
+	Software:       "Logiciel",
+	BlogPost:       "Billet de blog",
+	SocialPost:     "Publication sur %s",
+	StatusUpdate:   "Mise à jour de statut sur %s",
+	Months: [12]string{"janvier", "février", "mars", "avril", "mai", "juin",
+		"juillet", "août", "septembre", "octobre", "novembre", "décembre"},
+	ordinal: func(n int) string {
+		if n == 1 {
+			return "1re"
+		}
+		return fmt.Sprintf("%de", n)
+	},
+	dateFormat: func(day int, month string, year int) string {
+		return fmt.Sprintf("%d %s %d", day, month, year)
+	},
+	monthDay: func(day int, month string) string {
+		return fmt.Sprintf("%d %s", day, month)
+	},
+}
+
+var locales = map[string]*Locale{
+	"en": English,
+	"de": German,
+	"es": Spanish,
+	"fr": French,
+}
+
+// LookupLocale returns the catalog for a language code such as "de" or
+// "es-MX". Region subtags fall back to the base language.
+func LookupLocale(code string) (*Locale, error) {
+	lang := strings.ToLower(strings.TrimSpace(code))
+	if i := strings.IndexAny(lang, "-_"); i >= 0 {
+		lang = lang[:i]
+	}
+
+	if lang == "" {
+		return English, nil
+	}
+
+	loc, ok := locales[lang]
+	if !ok {
+		return nil, fmt.Errorf("unsupported locale: %s", code)
+	}
+
+	return loc, nil
+}
+
+// FormatDate renders a full date with localized month names, as used in
+// retrieval statements.
+func (l *Locale) FormatDate(t time.Time) string {
+	return l.dateFormat(t.Day(), l.Months[t.Month()-1], t.Year())
+}
+
+// formatMonthDay renders the month and day that follow the year of a dated
+// web page or newspaper article, or just the month when the day is unknown.
+func (l *Locale) formatMonthDay(month, day int) string {
+	if day == 0 {
+		return l.Months[month-1]
+	}
+	return l.monthDay(day, l.Months[month-1])
+}
+
+// RetrievedFrom renders a retrieval statement, including the access date
+// when one is given.
+func (l *Locale) RetrievedFrom(accessed time.Time, url string) string {
+	if accessed.IsZero() {
+		return fmt.Sprintf(l.Retrieved, url)
+	}
+	return fmt.Sprintf(l.RetrievedOn, l.FormatDate(accessed), url)
+}
+
+// postLabel returns the bracketed description of a social media post on
+// platform, e.g. "Twitter post".
+func (l *Locale) postLabel(platform string) string {
+	if platform == "" {
+		platform = "Social media"
+	}
+	if strings.EqualFold(platform, "Facebook") {
+		return fmt.Sprintf(l.StatusUpdate, platform)
+	}
+	return fmt.Sprintf(l.SocialPost, platform)
+}
+
+// editorLabel picks the singular or plural editor abbreviation for a name list.
+func (l *Locale) editorLabel(names string) string {
+	if len(bibtex.SplitNames(names)) > 1 {
+		return l.Editors
+	}
+	return l.Editor
+}
+
+// joinLast returns the separator placed before the last name in a list.
+func (l *Locale) joinLast() string {
+	if l.SerialComma {
+		return fmt.Sprintf(", %s ", l.And)
+	}
+	return fmt.Sprintf(" %s ", l.And)
+}
@@ -0,0 +1,143 @@
+package apa
+
+import (
+	"testing"
+	"time"
+
+	"github.com/knhn1004/bibtext-to-apa6/internal/bibtex"
+)
+
+func TestLookupLocale(t *testing.T) {
+	tests := []struct {
+		code    string
+		want    *Locale
+		wantErr bool
+	}{
+		{"", English, false},
+		{"en", English, false},
+		{"en-GB", English, false},
+		{"DE", German, false},
+		{"de_AT", German, false},
+		{"es-MX", Spanish, false},
+		{" fr ", French, false},
+		{"pt", nil, true},
+	}
+
+	for _, tt := range tests {
+		got, err := LookupLocale(tt.code)
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Errorf("LookupLocale(%q) = %v, %v, want %v", tt.code, got, err, tt.want)
+		}
+	}
+}
+
+func TestLocaleFormatDate(t *testing.T) {
+	date := time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		loc  *Locale
+		want string
+	}{
+		{English, "May 1, 2024"},
+		{German, "1. Mai 2024"},
+		{Spanish, "1 de mayo de 2024"},
+		{French, "1 mai 2024"},
+	}
+
+	for _, tt := range tests {
+		if got := tt.loc.FormatDate(date); got != tt.want {
+			t.Errorf("%s: FormatDate() = %q, want %q", tt.loc.Code, got, tt.want)
+		}
+	}
+}
+
+func TestLocaleRetrievedFrom(t *testing.T) {
+	accessed := time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)
+	url := "https://example.com/rivers"
+
+	tests := []struct {
+		loc         *Locale
+		want        string
+		wantUndated string
+	}{
+		{English, "Retrieved May 1, 2024, from " + url, "Retrieved from " + url},
+		{German, "Abgerufen am 1. Mai 2024 von " + url, "Abgerufen von " + url},
+		{Spanish, "Recuperado el 1 de mayo de 2024 de " + url, "Recuperado de " + url},
+		{French, "Consulté le 1 mai 2024, à l'adresse " + url, "Repéré à " + url},
+	}
+
+	for _, tt := range tests {
+		if got := tt.loc.RetrievedFrom(accessed, url); got != tt.want {
+			t.Errorf("%s: RetrievedFrom() = %q, want %q", tt.loc.Code, got, tt.want)
+		}
+		if got := tt.loc.RetrievedFrom(time.Time{}, url); got != tt.wantUndated {
+			t.Errorf("%s: RetrievedFrom() without a date = %q, want %q", tt.loc.Code, got, tt.wantUndated)
+		}
+	}
+}
+
+// TestFormatLocale checks the terms each catalog contributes to a
+// reference: name lists, editions, editors, pages, missing authors and
+// dates, and retrieval statements.
+func TestFormatLocale(t *testing.T) {
+	entries := []*bibtex.Entry{
+		{Type: "book", Fields: map[string]string{
+			"author": "Doe, Jane and Smith, John and Lee, Ann", "title": "Rivers of the world",
+			"year": "2020", "edition": "2", "publisher": "Field Press",
+		}},
+		{Type: "incollection", Fields: map[string]string{
+			"author": "Doe, Jane", "editor": "Smith, John and Lee, Ann", "title": "Braided rivers",
+			"booktitle": "Rivers of the world", "pages": "45--67", "year": "2020", "publisher": "Field Press",
+		}},
+		{Type: "phdthesis", Fields: map[string]string{
+			"title": "Braided rivers", "school": "University of Oregon", "year": "2019",
+		}},
+		{Type: "misc", Fields: map[string]string{
+			"title": "River data", "url": "https://example.com/data", "urldate": "2024-05-01",
+		}},
+	}
+
+	tests := []struct {
+		loc  *Locale
+		want []string
+	}{
+		{English, []string{
+			"Doe, J., Smith, J., & Lee, A. (2020). *Rivers of the world* (2nd ed.). Field Press.",
+			"Doe, J. (2020). Braided rivers. In J. Smith & A. Lee (Eds.), *Rivers of the world* (pp. 45–67). Field Press.",
+			"Unknown (2019). *Braided rivers* [Doctoral dissertation]. University of Oregon.",
+			"*River data*. (n.d.). Retrieved May 1, 2024, from https://example.com/data",
+		}},
+		{German, []string{
+			"Doe, J., Smith, J., & Lee, A. (2020). *Rivers of the world* (2. Aufl.). Field Press.",
+			"Doe, J. (2020). Braided rivers. In J. Smith & A. Lee (Hrsg.), *Rivers of the world* (S. 45–67). Field Press.",
+			"Unbekannt (2019). *Braided rivers* [Dissertation]. University of Oregon.",
+			"*River data*. (o. J.). Abgerufen am 1. Mai 2024 von https://example.com/data",
+		}},
+		{Spanish, []string{
+			"Doe, J., Smith, J. y Lee, A. (2020). *Rivers of the world* (2.ª ed.). Field Press.",
+			"Doe, J. (2020). Braided rivers. En J. Smith y A. Lee (Eds.), *Rivers of the world* (pp. 45–67). Field Press.",
+			"Desconocido (2019). *Braided rivers* [Tesis doctoral]. University of Oregon.",
+			"*River data*. (s.f.). Recuperado el 1 de mayo de 2024 de https://example.com/data",
+		}},
+		{French, []string{
+			"Doe, J., Smith, J. et Lee, A. (2020). *Rivers of the world* (2e éd.). Field Press.",
+			"Doe, J. (2020). Braided rivers. Dans J. Smith et A. Lee (Éds.), *Rivers of the world* (p. 45–67). Field Press.",
+			"Inconnu (2019). *Braided rivers* [Thèse de doctorat]. University of Oregon.",
+			"*River data*. (s.d.). Consulté le 1 mai 2024, à l'adresse https://example.com/data",
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.loc.Code, func(t *testing.T) {
+			for i, entry := range entries {
+				got, err := FormatLocale(entry, tt.loc)
+				if err != nil {
+					t.Fatalf("FormatLocale() error = %v", err)
+				}
+				if got != tt.want[i] {
+					t.Errorf("FormatLocale(%s) =\n%s\nwant\n%s", entry.Type, got, tt.want[i])
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,133 @@
+package bibtex
+
+import (
+	"strings"
+)
+
+// Name is one person or organization in a name list such as the author
+// field. Organizations are written in braces, e.g. {World Health
+// Organization}, so that they are not split into family and given names.
+type Name struct {
+	Family    string
+	Given     string
+	Suffix    string // "Jr.", "III"
+	Corporate bool
+}
+
+// SplitNames splits a name list on "and", ignoring any "and" inside braces.
+func SplitNames(list string) []string {
+	names := []string{}
+	depth := 0
+	start := 0
+
+	for i := 0; i < len(list); i++ {
+		switch list[i] {
+		case '{':
+			depth++
+		case '}':
+			depth--
+		case ' ':
+			if depth == 0 && strings.HasPrefix(list[i:], " and ") {
+				names = appendName(names, list[start:i])
+				start = i + len(" and ")
+				i += len(" and ") - 1
+			}
+		}
+	}
+
+	return appendName(names, list[start:])
+}
+
+func appendName(names []string, name string) []string {
+	if name = strings.TrimSpace(name); name != "" {
+		names = append(names, name)
+	}
+	return names
+}
+
+// ParseNames parses every name in a name list.
+func ParseNames(list string) []Name {
+	names := []Name{}
+	for _, name := range SplitNames(list) {
+		names = append(names, ParseName(name))
+	}
+	return names
+}
+
+// ParseName reads a name written as "Family, Given", "Family, Suffix,
+// Given", "Given Family" or, for an organization, "{Name}".
+func ParseName(name string) Name {
+	name = strings.TrimSpace(name)
+
+	if IsCorporateName(name) {
+		return Name{Family: strings.TrimSpace(name[1 : len(name)-1]), Corporate: true}
+	}
+
+	parts := strings.Split(name, ",")
+	for i := range parts {
+		parts[i] = strings.TrimSpace(parts[i])
+	}
+
+	switch len(parts) {
+	case 2:
+		return Name{Family: parts[0], Given: parts[1]}
+	case 3:
+		return Name{Family: parts[0], Suffix: parts[1], Given: parts[2]}
+	}
+
+	words := strings.Fields(name)
+	if len(words) > 1 {
+		return Name{Family: words[len(words)-1], Given: strings.Join(words[:len(words)-1], " ")}
+	}
+
+	return Name{Family: name}
+}
+
+// IsCorporateName reports whether a name is wrapped in a single pair of
+// braces, which marks it as an organization.
+func IsCorporateName(name string) bool {
+	if len(name) < 2 || name[0] != '{' || name[len(name)-1] != '}' {
+		return false
+	}
+
+	depth := 0
+	for i := 0; i < len(name); i++ {
+		switch name[i] {
+		case '{':
+			depth++
+		case '}':
+			depth--
+			if depth == 0 && i < len(name)-1 {
+				// "{A} {B}": the first brace closes before the end
+				return false
+			}
+		}
+	}
+
+	return depth == 0
+}
+
+// String renders the name in BibTeX form.
+func (n Name) String() string {
+	switch {
+	case n.Corporate:
+		return "{" + n.Family + "}"
+	case n.Given == "":
+		return n.Family
+	case n.Suffix != "":
+		return n.Family + ", " + n.Suffix + ", " + n.Given
+	default:
+		return n.Family + ", " + n.Given
+	}
+}
+
+// FormatNames joins names into a BibTeX name list.
+func FormatNames(names []Name) string {
+	formatted := make([]string, 0, len(names))
+	for _, n := range names {
+		if n.Family != "" {
+			formatted = append(formatted, n.String())
+		}
+	}
+	return strings.Join(formatted, " and ")
+}
@@ -0,0 +1,86 @@
+package bibtex
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestSplitNames(t *testing.T) {
+	tests := []struct {
+		list string
+		want []string
+	}{
+		{"", []string{}},
+		{"Doe, Jane", []string{"Doe, Jane"}},
+		{"Doe, Jane and Smith, John", []string{"Doe, Jane", "Smith, John"}},
+		{"{Johnson and Johnson} and Doe, Jane", []string{"{Johnson and Johnson}", "Doe, Jane"}},
+		{"  Doe, Jane  and  and Smith ", []string{"Doe, Jane", "Smith"}},
+		{"Alexander, Sandra", []string{"Alexander, Sandra"}},
+	}
+
+	for _, tt := range tests {
+		if got := SplitNames(tt.list); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("SplitNames(%q) = %q, want %q", tt.list, got, tt.want)
+		}
+	}
+}
+
+func TestParseName(t *testing.T) {
+	tests := []struct {
+		name string
+		want Name
+	}{
+		{"Doe, Jane", Name{Family: "Doe", Given: "Jane"}},
+		{"King, Jr., Martin Luther", Name{Family: "King", Suffix: "Jr.", Given: "Martin Luther"}},
+		{"Jane Ann Doe", Name{Family: "Doe", Given: "Jane Ann"}},
+		{"Plato", Name{Family: "Plato"}},
+		{"{World Health Organization}", Name{Family: "World Health Organization", Corporate: true}},
+	}
+
+	for _, tt := range tests {
+		if got := ParseName(tt.name); got != tt.want {
+			t.Errorf("ParseName(%q) = %#v, want %#v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestIsCorporateName(t *testing.T) {
+	tests := []struct {
+		name string
+		want bool
+	}{
+		{"{NASA}", true},
+		{"{Johnson {\\&} Johnson}", true},
+		{"{A} {B}", false},
+		{"NASA", false},
+		{"{", false},
+		{"{unbalanced", false},
+	}
+
+	for _, tt := range tests {
+		if got := IsCorporateName(tt.name); got != tt.want {
+			t.Errorf("IsCorporateName(%q) = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestFormatNames(t *testing.T) {
+	names := []Name{
+		{Family: "Doe", Given: "Jane"},
+		{Family: "King", Given: "Martin Luther", Suffix: "Jr."},
+		{Family: "Plato"},
+		{Family: "World Health Organization", Corporate: true},
+		{Given: "nobody"},
+	}
+
+	want := "Doe, Jane and King, Jr., Martin Luther and Plato and {World Health Organization}"
+	got := FormatNames(names)
+	if got != want {
+		t.Errorf("FormatNames() = %q, want %q", got, want)
+	}
+
+	// The BibTeX form parses back to the same names
+	if parsed := ParseNames(got); !reflect.DeepEqual(parsed, names[:4]) {
+		t.Errorf("ParseNames(FormatNames()) = %#v, want %#v", parsed, names[:4])
+	}
+}
@@ -4,6 +4,8 @@ import (
 	"fmt"
 	"regexp"
 	"strings"
+
+	"github.com/knhn1004/bibtext-to-apa6/internal/doi"
 )
 
 type Entry struct {
@@ -29,7 +31,8 @@ func Parse(input string) (*Entry, error) {
 	}
 
 	fieldsStr := matches[3]
-	fieldRe := regexp.MustCompile(`(\w+)\s*=\s*\{([^}]*)\}|(\w+)\s*=\s*"([^"]*)"|(\w+)\s*=\s*([^,}]+)`)
+	// Braced values may nest braces two levels deep, as in {M{\"{u}}ller}
+	fieldRe := regexp.MustCompile(`(\w+)\s*=\s*\{((?:[^{}]|\{(?:[^{}]|\{[^{}]*\})*\})*)\}|(\w+)\s*=\s*"([^"]*)"|(\w+)\s*=\s*([^,}]+)`)
 	fieldMatches := fieldRe.FindAllStringSubmatch(fieldsStr, -1)
 
 	for _, match := range fieldMatches {
@@ -47,14 +50,62 @@ func Parse(input string) (*Entry, error) {
 		}
 
 		if key != "" {
-			value = cleanBibTeXValue(value)
+			if nameFields[key] {
+				value = cleanNames(value)
+			} else {
+				value = cleanBibTeXValue(value)
+			}
 			entry.Fields[key] = value
 		}
 	}
 
+	normalizeDOI(entry)
+
 	return entry, nil
 }
 
+// normalizeDOI cleans up the doi field, or fills it in from a DOI embedded
+// in the url or note fields.
+func normalizeDOI(entry *Entry) {
+	if value, ok := entry.Fields["doi"]; ok {
+		if d := doi.Normalize(value); d != "" {
+			entry.Fields["doi"] = d
+		}
+		return
+	}
+
+	for _, field := range []string{"url", "note", "howpublished"} {
+		if d := doi.Find(entry.Fields[field]); d != "" {
+			entry.Fields["doi"] = d
+			return
+		}
+	}
+}
+
+// nameFields hold name lists, whose braced organization names are kept.
+var nameFields = map[string]bool{
+	"author":     true,
+	"editor":     true,
+	"translator": true,
+}
+
+// cleanNames cleans each name in a name list, keeping the braces around
+// organization names such as {World Health Organization}.
+func cleanNames(value string) string {
+	value = strings.Join(strings.Fields(value), " ")
+
+	names := []string{}
+	for _, name := range SplitNames(value) {
+		if IsCorporateName(name) {
+			names = append(names, "{"+cleanBibTeXValue(name[1:len(name)-1])+"}")
+		} else {
+			names = append(names, cleanBibTeXValue(name))
+		}
+	}
+
+	return strings.Join(names, " and ")
+}
+
 func cleanBibTeXValue(value string) string {
 	value = strings.TrimSpace(value)
 	value = strings.ReplaceAll(value, "\n", " ")
@@ -166,3 +217,17 @@ func (e *Entry) HasField(field string) bool {
 	_, ok := e.Fields[strings.ToLower(field)]
 	return ok
 }
+
+// TranslatedTitle returns the English translation of a title given in
+// another language or script, from the translatedtitle, titleaddon or usera
+// fields. Surrounding square brackets are removed.
+func (e *Entry) TranslatedTitle() string {
+	for _, field := range []string{"translatedtitle", "titleaddon", "usera"} {
+		value := strings.TrimSpace(e.GetField(field))
+		value = strings.TrimSpace(strings.TrimSuffix(strings.TrimPrefix(value, "["), "]"))
+		if value != "" {
+			return value
+		}
+	}
+	return ""
+}
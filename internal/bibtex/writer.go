@@ -0,0 +1,105 @@
+package bibtex
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// fieldOrder is the order in which well-known fields are written; any other
+// fields follow in alphabetical order.
+var fieldOrder = []string{
+	"author", "editor", "translator", "title", "translatedtitle",
+	"journal", "booktitle", "year", "month", "day", "volume", "number",
+	"pages", "eid", "edition", "series", "publisher", "organization",
+	"institution", "school", "address", "doi", "url", "urldate",
+}
+
+// String serializes the entry as BibTeX that Parse can read back.
+func (e *Entry) String() string {
+	keys := make([]string, 0, len(e.Fields))
+	seen := map[string]bool{}
+
+	for _, key := range fieldOrder {
+		if _, ok := e.Fields[key]; ok {
+			keys = append(keys, key)
+			seen[key] = true
+		}
+	}
+
+	rest := []string{}
+	for key := range e.Fields {
+		if !seen[key] {
+			rest = append(rest, key)
+		}
+	}
+	sort.Strings(rest)
+	keys = append(keys, rest...)
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "@%s{%s,\n", e.Type, e.Key)
+	for i, key := range keys {
+		fmt.Fprintf(&b, "  %s = {%s}", key, escapeValue(e.Fields[key]))
+		if i < len(keys)-1 {
+			b.WriteString(",")
+		}
+		b.WriteString("\n")
+	}
+	b.WriteString("}")
+
+	return b.String()
+}
+
+var keyCleaner = regexp.MustCompile(`[^a-z0-9]+`)
+
+// GenerateKey builds a citation key from the first author's or editor's
+// family name and the year, e.g. "smith2020", using fallback in place of the
+// name when there is none.
+func (e *Entry) GenerateKey(fallback string) string {
+	names := e.GetField("author")
+	if names == "" {
+		names = e.GetField("editor")
+	}
+
+	name := ""
+	if parsed := ParseNames(names); len(parsed) > 0 {
+		name = parsed[0].Family
+	}
+
+	key := keyCleaner.ReplaceAllString(strings.ToLower(name), "")
+	if key == "" {
+		key = keyCleaner.ReplaceAllString(strings.ToLower(fallback), "")
+	}
+
+	year := e.GetField("year")
+	if year == "" {
+		year = "nd"
+	}
+
+	return key + year
+}
+
+// escapeValue drops braces from a value so that it cannot close the field
+// early. Balanced braces, such as those around organization names, are kept.
+func escapeValue(value string) string {
+	depth := 0
+	for _, c := range value {
+		switch c {
+		case '{':
+			depth++
+		case '}':
+			depth--
+		}
+		if depth < 0 {
+			break
+		}
+	}
+	if depth == 0 {
+		return value
+	}
+
+	value = strings.ReplaceAll(value, "{", "")
+	value = strings.ReplaceAll(value, "}", "")
+	return value
+}
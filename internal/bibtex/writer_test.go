@@ -0,0 +1,145 @@
+package bibtex
+
+import (
+	"reflect"
+	"testing"
+)
+
+// TestEntryStringRoundTrip checks that Parse reads back what String writes.
+func TestEntryStringRoundTrip(t *testing.T) {
+	tests := []struct {
+		name  string
+		entry *Entry
+	}{
+		{
+			name: "article",
+			entry: &Entry{Type: "article", Key: "smith2020", Fields: map[string]string{
+				"author":  "Smith, John and Doe, Jane Ann and van der Berg, Anna",
+				"title":   "Sediment transport in braided rivers",
+				"journal": "Journal of Hydrology",
+				"year":    "2020",
+				"volume":  "12",
+				"number":  "3",
+				"pages":   "45--67",
+				"doi":     "10.1016/j.jhydrol.2020.01.001",
+			}},
+		},
+		{
+			name: "special characters",
+			entry: &Entry{Type: "techreport", Key: "who2019", Fields: map[string]string{
+				"author":      "{World Health Organization} and King, Jr., Martin Luther",
+				"title":       "50% of R&D budgets: \"costs\", risks = rewards",
+				"institution": "Johnson & Johnson",
+				"year":        "2019",
+				"note":        "See section 4 #2 and appendix_b",
+			}},
+		},
+		{
+			name: "corporate names with and",
+			entry: &Entry{Type: "misc", Key: "jj2021", Fields: map[string]string{
+				"author":    "{Johnson and Johnson} and {Procter and Gamble}",
+				"title":     "Annual report",
+				"url":       "https://example.com/report?year=2021&format=pdf",
+				"urldate":   "2021-03-03",
+				"publisher": "Müller & Søn",
+			}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Parse(tt.entry.String())
+			if err != nil {
+				t.Fatalf("Parse() error = %v\n%s", err, tt.entry)
+			}
+			if !reflect.DeepEqual(got, tt.entry) {
+				t.Errorf("Parse(String()) = %#v\nwant %#v\nfrom\n%s", got, tt.entry, tt.entry)
+			}
+		})
+	}
+}
+
+func TestEntryStringFieldOrder(t *testing.T) {
+	entry := &Entry{Type: "book", Key: "doe2020", Fields: map[string]string{
+		"zzz":       "last",
+		"publisher": "Field Press",
+		"title":     "Rivers",
+		"author":    "Doe, Jane",
+		"abstract":  "About rivers",
+		"year":      "2020",
+	}}
+
+	want := "@book{doe2020,\n" +
+		"  author = {Doe, Jane},\n" +
+		"  title = {Rivers},\n" +
+		"  year = {2020},\n" +
+		"  publisher = {Field Press},\n" +
+		"  abstract = {About rivers},\n" +
+		"  zzz = {last}\n" +
+		"}"
+	if got := entry.String(); got != want {
+		t.Errorf("String() =\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestEscapeValue(t *testing.T) {
+	tests := []struct {
+		value string
+		want  string
+	}{
+		{"plain", "plain"},
+		{"100% R&D", "100% R&D"},
+		{"The {DNA} of {NASA}", "The {DNA} of {NASA}"},
+		{"{nested {braces}}", "{nested {braces}}"},
+		{"unclosed {brace", "unclosed brace"},
+		{"stray } brace", "stray  brace"},
+		{"}{", ""},
+	}
+
+	for _, tt := range tests {
+		if got := escapeValue(tt.value); got != tt.want {
+			t.Errorf("escapeValue(%q) = %q, want %q", tt.value, got, tt.want)
+		}
+	}
+}
+
+// TestEscapeValueRoundTrip checks that a value with unbalanced braces cannot
+// close its field early and swallow the fields after it.
+func TestEscapeValueRoundTrip(t *testing.T) {
+	entry := &Entry{Type: "misc", Key: "x", Fields: map[string]string{
+		"title": "A } b {",
+		"year":  "2020",
+	}}
+
+	got, err := Parse(entry.String())
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	if got.Fields["title"] != "A b" || got.Fields["year"] != "2020" {
+		t.Errorf("Fields = %q, want title %q and year %q", got.Fields, "A b", "2020")
+	}
+}
+
+func TestGenerateKey(t *testing.T) {
+	tests := []struct {
+		name     string
+		fields   map[string]string
+		fallback string
+		want     string
+	}{
+		{"author", map[string]string{"author": "Smith, John and Doe, Jane", "year": "2020"}, "", "smith2020"},
+		{"particle", map[string]string{"author": "van der Berg, Anna and Smith, John", "year": "2019"}, "", "vanderberg2019"},
+		{"corporate", map[string]string{"author": "{World Health Organization}", "year": "2021"}, "", "worldhealthorganization2021"},
+		{"editor", map[string]string{"editor": "Doe, Jane", "year": "2017"}, "", "doe2017"},
+		{"undated", map[string]string{"author": "Doe, Jane"}, "", "doend"},
+		{"fallback", map[string]string{"year": "2022"}, "Field Notes!", "fieldnotes2022"},
+		{"nothing", map[string]string{}, "", "nd"},
+	}
+
+	for _, tt := range tests {
+		entry := &Entry{Type: "misc", Fields: tt.fields}
+		if got := entry.GenerateKey(tt.fallback); got != tt.want {
+			t.Errorf("%s: GenerateKey(%q) = %q, want %q", tt.name, tt.fallback, got, tt.want)
+		}
+	}
+}
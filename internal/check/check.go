@@ -0,0 +1,210 @@
+// Package check re-checks the URLs and DOIs of stored references and reports
+// the ones that have rotted.
+package check
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/knhn1004/bibtext-to-apa6/internal/bibtex"
+	"github.com/knhn1004/bibtext-to-apa6/internal/db"
+	"github.com/knhn1004/bibtext-to-apa6/internal/doi"
+	"github.com/knhn1004/bibtext-to-apa6/internal/url"
+)
+
+// Kinds of link checked for a reference.
+const (
+	KindURL = "url"
+	KindDOI = "doi"
+)
+
+// severity orders link statuses from best to worst, for summarizing a
+// reference with both a URL and a DOI.
+var severity = map[url.LinkStatus]int{
+	url.LinkOK:         0,
+	url.LinkRedirected: 1,
+	url.LinkMoved:      2,
+	url.LinkError:      3,
+	url.LinkDead:       4,
+}
+
+// Result is the outcome of checking one link of a reference.
+type Result struct {
+	ReferenceID  int    `json:"reference_id"`
+	ReferenceNum int    `json:"reference_num"`
+	Kind         string `json:"kind"`
+	url.LinkResult
+
+	// ArchiveURL is the reference's archived copy, reported for dead links.
+	ArchiveURL string `json:"archive_url,omitempty"`
+}
+
+// Checker checks the links of references.
+type Checker struct {
+	Links      *url.LinkChecker
+	DOIBaseURL string
+}
+
+// NewChecker returns a Checker that resolves DOIs through doi.org.
+func NewChecker() *Checker {
+	return &Checker{
+		Links:      url.NewLinkChecker(),
+		DOIBaseURL: url.DefaultDOIBaseURL,
+	}
+}
+
+// Run checks the URL and DOI of every reference that has one. Results are
+// in reference order, the URL before the DOI.
+func (c *Checker) Run(ctx context.Context, refs []*db.Reference) []Result {
+	var (
+		results []Result
+		links   []string
+	)
+
+	baseURL := strings.TrimRight(c.DOIBaseURL, "/")
+	if baseURL == "" {
+		baseURL = url.DefaultDOIBaseURL
+	}
+
+	for _, ref := range refs {
+		entry, err := bibtex.Parse(ref.BibtexEntry)
+		if err != nil {
+			continue
+		}
+
+		if link := entry.GetField("url"); link != "" {
+			results = append(results, Result{ReferenceID: ref.ID, ReferenceNum: ref.ReferenceNum, Kind: KindURL, ArchiveURL: ref.ArchiveURL})
+			links = append(links, link)
+		}
+		if d, err := doi.Parse(entry.GetField("doi")); err == nil {
+			results = append(results, Result{ReferenceID: ref.ID, ReferenceNum: ref.ReferenceNum, Kind: KindDOI})
+			links = append(links, baseURL+"/"+doi.Escape(d))
+		}
+	}
+
+	checker := c.Links
+	if checker == nil {
+		checker = url.NewLinkChecker()
+	}
+
+	for i, link := range checker.CheckAll(ctx, links) {
+		results[i].LinkResult = link
+		if results[i].Kind == KindDOI {
+			results[i].Status = doiStatus(link)
+			if results[i].Status == url.LinkOK {
+				results[i].Error = ""
+			}
+		}
+		if results[i].Status != url.LinkDead {
+			results[i].ArchiveURL = ""
+		}
+	}
+
+	return results
+}
+
+// doiStatus reinterprets a check of a DOI link. The resolver always
+// redirects to the publisher's site, so that is not a change; and a DOI that
+// resolved is fine even if the publisher turns away automated requests.
+func doiStatus(link url.LinkResult) url.LinkStatus {
+	if len(link.Redirects) == 0 {
+		return link.Status
+	}
+
+	switch link.Status {
+	case url.LinkRedirected, url.LinkMoved:
+		return url.LinkOK
+	case url.LinkError:
+		switch link.StatusCode {
+		case http.StatusUnauthorized, http.StatusForbidden, http.StatusTooManyRequests:
+			return url.LinkOK
+		}
+	}
+	return link.Status
+}
+
+// ReferenceStatus returns the worst status among the results for each
+// reference, keyed by reference ID.
+func ReferenceStatus(results []Result) map[int]url.LinkStatus {
+	statuses := make(map[int]url.LinkStatus)
+	for _, r := range results {
+		current, ok := statuses[r.ReferenceID]
+		if !ok || severity[r.Status] > severity[current] {
+			statuses[r.ReferenceID] = r.Status
+		}
+	}
+	return statuses
+}
+
+// Save stores the time and outcome of the check on each reference.
+func Save(database *db.DB, results []Result) error {
+	statuses := ReferenceStatus(results)
+	for _, r := range results {
+		status, ok := statuses[r.ReferenceID]
+		if !ok {
+			continue
+		}
+		delete(statuses, r.ReferenceID)
+
+		if err := database.SetCheckResult(r.ReferenceID, r.CheckedAt, string(status)); err != nil {
+			return fmt.Errorf("failed to save check of reference %d: %w", r.ReferenceNum, err)
+		}
+	}
+	return nil
+}
+
+// WriteTable writes the results as an aligned table, one row per link.
+func WriteTable(w io.Writer, results []Result) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "#\tKIND\tSTATUS\tCODE\tLINK\tDETAILS")
+
+	for _, r := range results {
+		code := "-"
+		if r.StatusCode != 0 {
+			code = fmt.Sprint(r.StatusCode)
+		}
+		fmt.Fprintf(tw, "%d\t%s\t%s\t%s\t%s\t%s\n", r.ReferenceNum, r.Kind, r.Status, code, r.URL, details(r))
+	}
+
+	return tw.Flush()
+}
+
+// details describes the redirect chain, error or archived copy of a result.
+func details(r Result) string {
+	var parts []string
+
+	if len(r.Redirects) > 0 && r.Status != url.LinkOK {
+		chain := []string{}
+		for _, hop := range r.Redirects {
+			chain = append(chain, fmt.Sprintf("%d %s", hop.StatusCode, hop.URL))
+		}
+		parts = append(parts, "-> "+strings.Join(chain, " -> "))
+	}
+	if r.Error != "" {
+		parts = append(parts, r.Error)
+	}
+	if r.ArchiveURL != "" {
+		parts = append(parts, "archived at "+r.ArchiveURL)
+	}
+
+	return strings.Join(parts, "; ")
+}
+
+// WriteJSON writes the results as an indented JSON array.
+func WriteJSON(w io.Writer, results []Result) error {
+	if results == nil {
+		results = []Result{}
+	}
+
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(results); err != nil {
+		return fmt.Errorf("failed to encode results: %w", err)
+	}
+	return nil
+}
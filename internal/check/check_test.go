@@ -0,0 +1,51 @@
+package check
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/knhn1004/bibtext-to-apa6/internal/db"
+	"github.com/knhn1004/bibtext-to-apa6/internal/url"
+)
+
+func TestRunArchiveURL(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/gone" {
+			w.WriteHeader(http.StatusGone)
+			return
+		}
+		w.Write([]byte("<html></html>"))
+	}))
+	defer server.Close()
+
+	refs := []*db.Reference{
+		{
+			ID:           1,
+			ReferenceNum: 1,
+			BibtexEntry:  "@misc{gone, title = {Gone}, url = {" + server.URL + "/gone}}",
+			ArchiveURL:   "https://web.archive.org/web/20230105100000/https://example.com/gone",
+		},
+		{
+			ID:           2,
+			ReferenceNum: 2,
+			BibtexEntry:  "@misc{live, title = {Live}, url = {" + server.URL + "/live}}",
+			ArchiveURL:   "https://web.archive.org/web/20230105100000/https://example.com/live",
+		},
+	}
+
+	checker := &Checker{Links: &url.LinkChecker{Client: server.Client(), Workers: 2}}
+	results := checker.Run(context.Background(), refs)
+	if len(results) != 2 {
+		t.Fatalf("Run() returned %d results, want 2", len(results))
+	}
+
+	// Only a dead link falls back to its archived copy
+	if results[0].Status != url.LinkDead || results[0].ArchiveURL != refs[0].ArchiveURL {
+		t.Errorf("dead link = %s, archive %q, want dead with the archived copy", results[0].Status, results[0].ArchiveURL)
+	}
+	if results[1].Status != url.LinkOK || results[1].ArchiveURL != "" {
+		t.Errorf("live link = %s, archive %q, want ok without an archived copy", results[1].Status, results[1].ArchiveURL)
+	}
+}
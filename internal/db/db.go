@@ -26,6 +26,9 @@ type Reference struct {
 	BibtexEntry  string
 	APAFormat    string
 	SourceType   string
+	ArchiveURL   string    // Wayback Machine snapshot of the reference's URL
+	CheckedAt    time.Time // last link check, zero if never checked
+	CheckStatus  string    // outcome of the last link check
 	CreatedAt    time.Time
 }
 
@@ -57,9 +60,19 @@ func (db *DB) Migrate() error {
 			bibtex_entry TEXT,
 			apa_format TEXT NOT NULL,
 			source_type TEXT NOT NULL,
+			archive_url TEXT,
+			checked_at DATETIME,
+			check_status TEXT,
 			created_at DATETIME DEFAULT CURRENT_TIMESTAMP
 		)`,
 		`CREATE INDEX IF NOT EXISTS idx_citations_project_id ON citations(project_id)`,
+		`CREATE TABLE IF NOT EXISTS snapshots (
+			citation_id INTEGER PRIMARY KEY,
+			url TEXT NOT NULL,
+			content_type TEXT,
+			html BLOB NOT NULL,
+			fetched_at DATETIME NOT NULL
+		)`,
 	}
 
 	for _, query := range queries {
@@ -68,6 +81,16 @@ func (db *DB) Migrate() error {
 		}
 	}
 
+	for _, column := range [][2]string{
+		{"archive_url", "TEXT"},
+		{"checked_at", "DATETIME"},
+		{"check_status", "TEXT"},
+	} {
+		if err := db.addColumn(column[0], column[1]); err != nil {
+			return err
+		}
+	}
+
 	// Check if reference_num column exists and add it if not
 	var count int
 	err := db.conn.QueryRow(`SELECT COUNT(*) FROM pragma_table_info('citations') WHERE name='reference_num'`).Scan(&count)
@@ -111,3 +134,20 @@ func (db *DB) Migrate() error {
 
 	return nil
 }
+
+// addColumn adds a column to the citations table of databases created before
+// it existed.
+func (db *DB) addColumn(name, definition string) error {
+	var count int
+	if err := db.conn.QueryRow(`SELECT COUNT(*) FROM pragma_table_info('citations') WHERE name = ?`, name).Scan(&count); err != nil {
+		return fmt.Errorf("failed to inspect citations table: %w", err)
+	}
+	if count > 0 {
+		return nil
+	}
+
+	if _, err := db.conn.Exec(fmt.Sprintf(`ALTER TABLE citations ADD COLUMN %s %s`, name, definition)); err != nil {
+		return fmt.Errorf("failed to add %s column: %v", name, err)
+	}
+	return nil
+}
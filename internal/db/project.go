@@ -84,7 +84,11 @@ func (db *DB) GetOrCreateProject(name string) (*Project, error) {
 
 func (db *DB) DeleteProject(id int) error {
 	// Delete all references associated with the project first
-	_, err := db.conn.Exec(`DELETE FROM citations WHERE project_id = ?`, id)
+	_, err := db.conn.Exec(`DELETE FROM snapshots WHERE citation_id IN (SELECT id FROM citations WHERE project_id = ?)`, id)
+	if err != nil {
+		return fmt.Errorf("failed to delete project snapshots: %w", err)
+	}
+	_, err = db.conn.Exec(`DELETE FROM citations WHERE project_id = ?`, id)
 	if err != nil {
 		return fmt.Errorf("failed to delete project references: %w", err)
 	}
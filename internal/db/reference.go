@@ -1,12 +1,38 @@
 package db
 
 import (
+	"database/sql"
 	"fmt"
 	"strings"
 	"time"
 )
 
+// referenceColumns are the citations columns read by scanReference.
+const referenceColumns = `id, project_id, reference_num, bibtex_entry, apa_format, source_type, COALESCE(archive_url, ''), checked_at, COALESCE(check_status, ''), created_at`
+
+// rowScanner is a *sql.Row or *sql.Rows.
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanReference(row rowScanner) (*Reference, error) {
+	var (
+		r         Reference
+		checkedAt sql.NullTime
+	)
+	err := row.Scan(&r.ID, &r.ProjectID, &r.ReferenceNum, &r.BibtexEntry, &r.APAFormat, &r.SourceType, &r.ArchiveURL, &checkedAt, &r.CheckStatus, &r.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+	r.CheckedAt = checkedAt.Time
+	return &r, nil
+}
+
 func (db *DB) AddReference(projectID int, bibtexEntry, apaFormat, sourceType string) (*Reference, error) {
+	if strings.TrimSpace(apaFormat) == "" {
+		return nil, fmt.Errorf("reference has no APA citation")
+	}
+
 	// Check if reference already exists
 	exists, err := db.ReferenceExists(projectID, apaFormat)
 	if err != nil {
@@ -46,7 +72,7 @@ func (db *DB) AddReference(projectID int, bibtexEntry, apaFormat, sourceType str
 }
 
 func (db *DB) ListReferences(projectID int) ([]*Reference, error) {
-	query := `SELECT id, project_id, reference_num, bibtex_entry, apa_format, source_type, created_at 
+	query := `SELECT ` + referenceColumns + `
 	          FROM citations 
 	          WHERE project_id = ? 
 	          ORDER BY reference_num`
@@ -59,12 +85,11 @@ func (db *DB) ListReferences(projectID int) ([]*Reference, error) {
 
 	var references []*Reference
 	for rows.Next() {
-		var r Reference
-		err := rows.Scan(&r.ID, &r.ProjectID, &r.ReferenceNum, &r.BibtexEntry, &r.APAFormat, &r.SourceType, &r.CreatedAt)
+		r, err := scanReference(rows)
 		if err != nil {
 			return nil, err
 		}
-		references = append(references, &r)
+		references = append(references, r)
 	}
 
 	return references, rows.Err()
@@ -72,20 +97,57 @@ func (db *DB) ListReferences(projectID int) ([]*Reference, error) {
 
 func (db *DB) DeleteReference(id int) error {
 	query := `DELETE FROM citations WHERE id = ?`
-	_, err := db.conn.Exec(query, id)
-	return err
+	if _, err := db.conn.Exec(query, id); err != nil {
+		return err
+	}
+	return db.DeleteSnapshot(id)
 }
 
 func (db *DB) GetReference(id int) (*Reference, error) {
-	query := `SELECT id, project_id, reference_num, bibtex_entry, apa_format, source_type, created_at FROM citations WHERE id = ?`
+	query := `SELECT ` + referenceColumns + ` FROM citations WHERE id = ?`
 
-	var r Reference
-	err := db.conn.QueryRow(query, id).Scan(&r.ID, &r.ProjectID, &r.ReferenceNum, &r.BibtexEntry, &r.APAFormat, &r.SourceType, &r.CreatedAt)
+	r, err := scanReference(db.conn.QueryRow(query, id))
 	if err != nil {
 		return nil, fmt.Errorf("reference not found")
 	}
 
-	return &r, nil
+	return r, nil
+}
+
+// UpdateReference replaces the BibTeX entry and APA format of a reference.
+func (db *DB) UpdateReference(id int, bibtexEntry, apaFormat string) error {
+	result, err := db.conn.Exec(`UPDATE citations SET bibtex_entry = ?, apa_format = ? WHERE id = ?`, bibtexEntry, apaFormat, id)
+	if err != nil {
+		return fmt.Errorf("failed to update reference: %w", err)
+	}
+	if n, err := result.RowsAffected(); err == nil && n == 0 {
+		return fmt.Errorf("reference not found")
+	}
+	return nil
+}
+
+// SetArchiveURL stores the archived copy of a reference's URL.
+func (db *DB) SetArchiveURL(id int, archiveURL string) error {
+	result, err := db.conn.Exec(`UPDATE citations SET archive_url = ? WHERE id = ?`, archiveURL, id)
+	if err != nil {
+		return fmt.Errorf("failed to set archive URL: %w", err)
+	}
+	if n, err := result.RowsAffected(); err == nil && n == 0 {
+		return fmt.Errorf("reference not found")
+	}
+	return nil
+}
+
+// SetCheckResult records the time and outcome of a link check.
+func (db *DB) SetCheckResult(id int, checkedAt time.Time, status string) error {
+	result, err := db.conn.Exec(`UPDATE citations SET checked_at = ?, check_status = ? WHERE id = ?`, checkedAt, status, id)
+	if err != nil {
+		return fmt.Errorf("failed to set check result: %w", err)
+	}
+	if n, err := result.RowsAffected(); err == nil && n == 0 {
+		return fmt.Errorf("reference not found")
+	}
+	return nil
 }
 
 func (db *DB) ReferenceExists(projectID int, apaFormat string) (bool, error) {
@@ -114,7 +176,7 @@ func (db *DB) GetReferencesByNumbers(projectID int, numbers []int) ([]*Reference
 	}
 
 	query := fmt.Sprintf(`
-		SELECT id, project_id, reference_num, bibtex_entry, apa_format, source_type, created_at 
+		SELECT `+referenceColumns+`
 		FROM citations 
 		WHERE project_id = ? AND reference_num IN (%s)
 		ORDER BY reference_num
@@ -128,12 +190,11 @@ func (db *DB) GetReferencesByNumbers(projectID int, numbers []int) ([]*Reference
 
 	var references []*Reference
 	for rows.Next() {
-		var r Reference
-		err := rows.Scan(&r.ID, &r.ProjectID, &r.ReferenceNum, &r.BibtexEntry, &r.APAFormat, &r.SourceType, &r.CreatedAt)
+		r, err := scanReference(rows)
 		if err != nil {
 			return nil, err
 		}
-		references = append(references, &r)
+		references = append(references, r)
 	}
 
 	return references, rows.Err()
@@ -0,0 +1,79 @@
+package db
+
+import (
+	"bytes"
+	"compress/gzip"
+	"database/sql"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+)
+
+// Snapshot is the HTML of a URL reference's page as it was fetched, kept so
+// that metadata can be extracted again from the page the reference was
+// made from.
+type Snapshot struct {
+	ReferenceID int
+	URL         string // final URL of the page
+	ContentType string // Content-Type header, naming the page's charset
+	HTML        []byte
+	FetchedAt   time.Time
+}
+
+// SaveSnapshot stores the page a reference was extracted from, replacing
+// any earlier snapshot. The HTML is stored gzip-compressed.
+func (db *DB) SaveSnapshot(s *Snapshot) error {
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	if _, err := zw.Write(s.HTML); err != nil {
+		return fmt.Errorf("failed to compress snapshot: %w", err)
+	}
+	if err := zw.Close(); err != nil {
+		return fmt.Errorf("failed to compress snapshot: %w", err)
+	}
+
+	_, err := db.conn.Exec(`INSERT OR REPLACE INTO snapshots (citation_id, url, content_type, html, fetched_at) VALUES (?, ?, ?, ?, ?)`,
+		s.ReferenceID, s.URL, s.ContentType, buf.Bytes(), s.FetchedAt)
+	if err != nil {
+		return fmt.Errorf("failed to save snapshot: %w", err)
+	}
+	return nil
+}
+
+// GetSnapshot returns the stored page of a reference, or nil if it has none.
+func (db *DB) GetSnapshot(referenceID int) (*Snapshot, error) {
+	var (
+		s           Snapshot
+		contentType sql.NullString
+		compressed  []byte
+	)
+	err := db.conn.QueryRow(`SELECT citation_id, url, content_type, html, fetched_at FROM snapshots WHERE citation_id = ?`, referenceID).
+		Scan(&s.ReferenceID, &s.URL, &contentType, &compressed, &s.FetchedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get snapshot: %w", err)
+	}
+	s.ContentType = contentType.String
+
+	zr, err := gzip.NewReader(bytes.NewReader(compressed))
+	if err != nil {
+		return nil, fmt.Errorf("failed to decompress snapshot: %w", err)
+	}
+	defer zr.Close()
+
+	if s.HTML, err = io.ReadAll(zr); err != nil {
+		return nil, fmt.Errorf("failed to decompress snapshot: %w", err)
+	}
+	return &s, nil
+}
+
+// DeleteSnapshot removes the stored page of a reference, if any.
+func (db *DB) DeleteSnapshot(referenceID int) error {
+	if _, err := db.conn.Exec(`DELETE FROM snapshots WHERE citation_id = ?`, referenceID); err != nil {
+		return fmt.Errorf("failed to delete snapshot: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,136 @@
+package db
+
+import (
+	"bytes"
+	"fmt"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func openTestDB(t *testing.T) *DB {
+	t.Helper()
+
+	database, err := NewDB(filepath.Join(t.TempDir(), "refs.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { database.Close() })
+
+	if err := database.Migrate(); err != nil {
+		t.Fatal(err)
+	}
+	return database
+}
+
+func TestSnapshotRoundTrip(t *testing.T) {
+	database := openTestDB(t)
+
+	project, err := database.CreateProject("test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	ref, err := database.AddReference(project.ID, "", "Doe, J. (2022). Rivers.", "url")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if s, err := database.GetSnapshot(ref.ID); err != nil || s != nil {
+		t.Fatalf("GetSnapshot() before saving = %v, %v, want nil", s, err)
+	}
+
+	want := &Snapshot{
+		ReferenceID: ref.ID,
+		URL:         "https://example.com/rivers",
+		ContentType: "text/html; charset=windows-1252",
+		HTML:        []byte("<html><title>Caf\xe9</title></html>"),
+		FetchedAt:   time.Date(2024, 5, 1, 12, 30, 0, 0, time.UTC),
+	}
+	if err := database.SaveSnapshot(want); err != nil {
+		t.Fatalf("SaveSnapshot() error = %v", err)
+	}
+
+	got, err := database.GetSnapshot(ref.ID)
+	if err != nil {
+		t.Fatalf("GetSnapshot() error = %v", err)
+	}
+	if got.ReferenceID != want.ReferenceID || got.URL != want.URL || got.ContentType != want.ContentType || !got.FetchedAt.Equal(want.FetchedAt) {
+		t.Errorf("GetSnapshot() = %+v, want %+v", got, want)
+	}
+	// The bytes come back as they were, whatever their encoding
+	if !bytes.Equal(got.HTML, want.HTML) {
+		t.Errorf("HTML = %q, want %q", got.HTML, want.HTML)
+	}
+
+	// Saving again replaces the snapshot
+	want.URL = "https://example.com/rivers-2"
+	want.ContentType = ""
+	want.HTML = []byte("<html></html>")
+	if err := database.SaveSnapshot(want); err != nil {
+		t.Fatalf("SaveSnapshot() error = %v", err)
+	}
+	got, err = database.GetSnapshot(ref.ID)
+	if err != nil {
+		t.Fatalf("GetSnapshot() error = %v", err)
+	}
+	if got.URL != want.URL || got.ContentType != "" || string(got.HTML) != "<html></html>" {
+		t.Errorf("GetSnapshot() after replacing = %+v, want %+v", got, want)
+	}
+
+	if err := database.DeleteSnapshot(ref.ID); err != nil {
+		t.Fatalf("DeleteSnapshot() error = %v", err)
+	}
+	if s, err := database.GetSnapshot(ref.ID); err != nil || s != nil {
+		t.Errorf("GetSnapshot() after DeleteSnapshot = %v, %v, want nil", s, err)
+	}
+	if err := database.DeleteSnapshot(ref.ID); err != nil {
+		t.Errorf("DeleteSnapshot() of a missing snapshot error = %v", err)
+	}
+
+	// The reference itself is kept
+	if _, err := database.GetReference(ref.ID); err != nil {
+		t.Errorf("GetReference() after DeleteSnapshot error = %v", err)
+	}
+}
+
+func TestDeleteReferenceSnapshot(t *testing.T) {
+	database := openTestDB(t)
+
+	project, err := database.CreateProject("test")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	refs := make([]*Reference, 2)
+	for i := range refs {
+		if refs[i], err = database.AddReference(project.ID, "", fmt.Sprintf("Reference %d.", i), "url"); err != nil {
+			t.Fatal(err)
+		}
+		err := database.SaveSnapshot(&Snapshot{
+			ReferenceID: refs[i].ID,
+			URL:         "https://example.com/",
+			HTML:        []byte("<html></html>"),
+			FetchedAt:   time.Now(),
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err := database.DeleteReference(refs[0].ID); err != nil {
+		t.Fatalf("DeleteReference() error = %v", err)
+	}
+	if s, err := database.GetSnapshot(refs[0].ID); err != nil || s != nil {
+		t.Errorf("GetSnapshot() of a deleted reference = %v, %v, want nil", s, err)
+	}
+	if s, err := database.GetSnapshot(refs[1].ID); err != nil || s == nil {
+		t.Errorf("GetSnapshot() of another reference = %v, %v, want it kept", s, err)
+	}
+
+	if err := database.DeleteProject(project.ID); err != nil {
+		t.Fatalf("DeleteProject() error = %v", err)
+	}
+	if s, err := database.GetSnapshot(refs[1].ID); err != nil || s != nil {
+		t.Errorf("GetSnapshot() after DeleteProject = %v, %v, want nil", s, err)
+	}
+}
@@ -0,0 +1,152 @@
+package doi
+
+import (
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+// doiPattern matches a bare DOI: the "10." directory indicator, a numeric
+// registrant code of any length and a non-empty suffix.
+var doiPattern = regexp.MustCompile(`^10\.\d+(\.\d+)*/\S+$`)
+
+// embeddedPattern finds DOIs inside free text such as URLs or notes. It asks
+// for the registrant codes of four or more digits that are actually issued,
+// so that numbers such as "10.5/2" in prose are not taken for DOIs.
+var embeddedPattern = regexp.MustCompile(`(?i)10\.\d{4,9}(?:\.\d+)*/[^\s"<>?#&]+`)
+
+// viewSuffix matches the trailing path segments publisher URLs add after a
+// DOI to select a view of the article, as in ".../10.1002/x/full".
+var viewSuffix = regexp.MustCompile(`(?i)(?:/(?:full|abstract|pdf|epdf|html))+/?$`)
+
+// prefixes are the resolver and scheme forms DOIs are commonly written with.
+var prefixes = []string{
+	"https://doi.org/",
+	"http://doi.org/",
+	"https://dx.doi.org/",
+	"http://dx.doi.org/",
+	"doi.org/",
+	"dx.doi.org/",
+	"doi:",
+	"doi ",
+	"info:doi/",
+	"urn:doi:",
+}
+
+// Parse extracts a DOI from a string such as "doi:10.1/x",
+// "https://dx.doi.org/10.1/x" or "10.1/x." and returns it in normalized form.
+func Parse(s string) (string, error) {
+	s = strings.TrimSpace(s)
+
+	lower := strings.ToLower(s)
+	for _, prefix := range prefixes {
+		if strings.HasPrefix(lower, prefix) {
+			s = strings.TrimSpace(s[len(prefix):])
+			break
+		}
+	}
+
+	if unescaped, err := url.PathUnescape(s); err == nil {
+		s = unescaped
+	}
+
+	s = trimTrailingPunctuation(s)
+
+	if !doiPattern.MatchString(s) {
+		return "", fmt.Errorf("invalid DOI: %q", s)
+	}
+
+	return s, nil
+}
+
+// Normalize returns the normalized DOI, or an empty string if s is not a DOI.
+func Normalize(s string) string {
+	d, err := Parse(s)
+	if err != nil {
+		return ""
+	}
+	return d
+}
+
+// IsValid reports whether s is a DOI in any of the forms accepted by Parse.
+func IsValid(s string) bool {
+	_, err := Parse(s)
+	return err == nil
+}
+
+// Find returns the first DOI embedded in text, such as a publisher URL or a
+// BibTeX note, or an empty string if there is none.
+func Find(text string) string {
+	unescaped, err := url.PathUnescape(text)
+	if err == nil {
+		text = unescaped
+	}
+
+	match := embeddedPattern.FindString(text)
+	if match == "" {
+		return ""
+	}
+
+	// Publisher URLs often continue past the DOI with a file or view suffix
+	match = viewSuffix.ReplaceAllString(match, "")
+
+	return Normalize(match)
+}
+
+// URL returns the https://doi.org form of a DOI, percent-encoding characters
+// that are not allowed in a URL path.
+func URL(d string) string {
+	return "https://doi.org/" + Escape(d)
+}
+
+// Escape percent-encodes the characters of a DOI that must not appear
+// literally in a URL, following the DOI Handbook recommendations.
+func Escape(d string) string {
+	var b strings.Builder
+	for _, c := range []byte(d) {
+		if shouldEscape(c) {
+			fmt.Fprintf(&b, "%%%02X", c)
+		} else {
+			b.WriteByte(c)
+		}
+	}
+	return b.String()
+}
+
+func shouldEscape(c byte) bool {
+	if c <= 0x20 || c >= 0x7f {
+		return true
+	}
+	switch c {
+	case '"', '#', '%', '<', '>', '?', '[', ']', '\\', '^', '`', '{', '|', '}':
+		return true
+	}
+	return false
+}
+
+// trimTrailingPunctuation removes sentence punctuation that is commonly
+// pasted along with a DOI, keeping closing brackets that are balanced
+// within the DOI itself, as in 10.1002/(SICI)1097-4571(199806)49:8<693::AID-ASI4>3.0.CO;2-0.
+func trimTrailingPunctuation(s string) string {
+	for len(s) > 0 {
+		last := s[len(s)-1]
+		switch last {
+		case '.', ',', ';', ':', '"', '\'':
+			s = s[:len(s)-1]
+			continue
+		case ')':
+			if strings.Count(s, "(") < strings.Count(s, ")") {
+				s = s[:len(s)-1]
+				continue
+			}
+		case ']':
+			if strings.Count(s, "[") < strings.Count(s, "]") {
+				s = s[:len(s)-1]
+				continue
+			}
+		}
+		break
+	}
+	return s
+}
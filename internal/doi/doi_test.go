@@ -0,0 +1,65 @@
+package doi
+
+import "testing"
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{"10.1000/xyz123", "10.1000/xyz123"},
+		{"doi:10.1000/xyz123", "10.1000/xyz123"},
+		{"DOI: 10.1000/xyz123", "10.1000/xyz123"},
+		{"https://doi.org/10.1000/xyz123", "10.1000/xyz123"},
+		{"http://dx.doi.org/10.1000/xyz123", "10.1000/xyz123"},
+		{"https://doi.org/10.1000/a%2Fb", "10.1000/a/b"},
+		{"10.1000/xyz123.", "10.1000/xyz123"},
+		{"10.1002/(SICI)1097-4571(199806)49:8<693::AID-ASI4>3.0.CO;2-0", "10.1002/(SICI)1097-4571(199806)49:8<693::AID-ASI4>3.0.CO;2-0"},
+		{"10.1/x", "10.1/x"},
+		{"doi:10.1/x", "10.1/x"},
+		{"https://dx.doi.org/10.1/x", "10.1/x"},
+		{"10.1/x.", "10.1/x"},
+		{"not a doi", ""},
+		{"10./x", ""},
+		{"10.1/", ""},
+		{"11.1000/xyz123", ""},
+	}
+
+	for _, tt := range tests {
+		if got := Normalize(tt.input); got != tt.want {
+			t.Errorf("Normalize(%q) = %q, want %q", tt.input, got, tt.want)
+		}
+	}
+}
+
+func TestFind(t *testing.T) {
+	tests := []struct {
+		text string
+		want string
+	}{
+		{"https://onlinelibrary.wiley.com/doi/10.1002/asi.24000/full", "10.1002/asi.24000"},
+		{"https://onlinelibrary.wiley.com/doi/10.1002/asi.24000/abstract", "10.1002/asi.24000"},
+		{"https://onlinelibrary.wiley.com/doi/epdf/10.1002/asi.24000", "10.1002/asi.24000"},
+		{"https://example.org/10.1002/asi.24000/full/pdf/", "10.1002/asi.24000"},
+		{"https://example.org/10.1002/asi.24000/HTML", "10.1002/asi.24000"},
+		{"https://pubs.acs.org/doi/10.1021/fullerene.2020.1", "10.1021/fullerene.2020.1"},
+		{"https://example.org/10.1000/abstracts-2021/7", "10.1000/abstracts-2021/7"},
+		{"https://example.org/10.1000/pdfa.3/full", "10.1000/pdfa.3"},
+		{"See 10.1000/xyz123, for details", "10.1000/xyz123"},
+		{"Rated 10.5/10 by critics", ""},
+		{"https://example.org/article?doi=10.1000%2Fxyz123&lang=en", "10.1000/xyz123"},
+		{"no identifier here", ""},
+	}
+
+	for _, tt := range tests {
+		if got := Find(tt.text); got != tt.want {
+			t.Errorf("Find(%q) = %q, want %q", tt.text, got, tt.want)
+		}
+	}
+}
+
+func TestURL(t *testing.T) {
+	if got, want := URL("10.1000/a<b>#c"), "https://doi.org/10.1000/a%3Cb%3E%23c"; got != want {
+		t.Errorf("URL() = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,521 @@
+// Package pdf reads the metadata and the leading text of PDF files. It
+// implements only the subset of PDF needed for that: objects, object
+// streams, the FlateDecode, ASCIIHexDecode and ASCII85Decode filters, the
+// Info dictionary, XMP metadata and the text operators of content streams.
+package pdf
+
+import (
+	"bytes"
+	"compress/flate"
+	"compress/zlib"
+	"encoding/ascii85"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf16"
+)
+
+// ErrEncrypted is returned for encrypted PDFs, whose strings cannot be read
+// without decrypting them.
+var ErrEncrypted = errors.New("encrypted PDFs are not supported")
+
+var objHeader = regexp.MustCompile(`(\d+)\s+(\d+)\s+obj\b`)
+
+// Document is a parsed PDF file.
+type Document struct {
+	objects map[int]interface{}
+	trailer dict
+}
+
+// Open reads and parses the PDF file at path.
+func Open(path string) (*Document, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read PDF: %w", err)
+	}
+	return Parse(data)
+}
+
+// Parse parses a PDF held in memory. Objects are found by scanning the file
+// rather than through the cross-reference table, so files with damaged or
+// missing tables can still be read.
+func Parse(data []byte) (*Document, error) {
+	if !bytes.HasPrefix(bytes.TrimLeft(data, " \t\r\n"), []byte("%PDF-")) {
+		return nil, fmt.Errorf("not a PDF file")
+	}
+
+	d := &Document{objects: map[int]interface{}{}, trailer: dict{}}
+	d.scanObjects(data)
+	d.readTrailer(data)
+	d.expandObjectStreams()
+
+	if _, ok := d.trailer["Encrypt"]; ok {
+		return nil, ErrEncrypted
+	}
+	if len(d.objects) == 0 {
+		return nil, fmt.Errorf("no objects found in PDF")
+	}
+
+	return d, nil
+}
+
+// scanObjects reads every "n g obj ... endobj" in the file. Later objects
+// replace earlier ones with the same number, as incremental updates do.
+func (d *Document) scanObjects(data []byte) {
+	pos := 0
+	for pos < len(data) {
+		loc := objHeader.FindSubmatchIndex(data[pos:])
+		if loc == nil {
+			return
+		}
+
+		num := atoi(data[pos+loc[2] : pos+loc[3]])
+		l := &lexer{data: data, pos: pos + loc[1]}
+		pos += loc[1]
+
+		obj, err := l.parseObject()
+		if err != nil {
+			continue
+		}
+
+		if dic, ok := obj.(dict); ok {
+			save := l.pos
+			if tok, _ := l.next(); tok == keyword("stream") {
+				s, end := readStream(data, l.pos, dic)
+				obj = s
+				l.pos = end
+			} else {
+				l.pos = save
+			}
+		}
+
+		d.objects[num] = obj
+		pos = l.pos
+	}
+}
+
+// readStream reads stream data starting just after the "stream" keyword,
+// returning the stream and the offset after "endstream".
+func readStream(data []byte, start int, dic dict) (*stream, int) {
+	// The keyword is followed by CRLF or LF
+	if start < len(data) && data[start] == '\r' {
+		start++
+	}
+	if start < len(data) && data[start] == '\n' {
+		start++
+	}
+
+	if length, ok := dic["Length"].(float64); ok {
+		end := start + int(length)
+		if end <= len(data) && end >= start {
+			rest := bytes.TrimLeft(data[end:], " \t\r\n")
+			if bytes.HasPrefix(rest, []byte("endstream")) {
+				after := len(data) - len(rest) + len("endstream")
+				return &stream{dict: dic, data: data[start:end]}, after
+			}
+		}
+	}
+
+	// Indirect or wrong /Length: find the end marker instead
+	i := bytes.Index(data[start:], []byte("endstream"))
+	if i < 0 {
+		return &stream{dict: dic, data: data[start:]}, len(data)
+	}
+	body := bytes.TrimRight(data[start:start+i], "\r\n")
+	return &stream{dict: dic, data: body}, start + i + len("endstream")
+}
+
+// readTrailer merges the classic trailer dictionaries and the dictionaries of
+// cross-reference streams; the last one in the file wins.
+func (d *Document) readTrailer(data []byte) {
+	for _, num := range sortedKeys(d.objects) {
+		if s, ok := d.objects[num].(*stream); ok && s.dict["Type"] == name("XRef") {
+			mergeTrailer(d.trailer, s.dict)
+		}
+	}
+
+	pos := 0
+	for {
+		i := bytes.Index(data[pos:], []byte("trailer"))
+		if i < 0 {
+			break
+		}
+		l := &lexer{data: data, pos: pos + i + len("trailer")}
+		if obj, err := l.parseObject(); err == nil {
+			if dic, ok := obj.(dict); ok {
+				mergeTrailer(d.trailer, dic)
+			}
+		}
+		pos += i + len("trailer")
+	}
+
+	// A truncated file loses its trailer; fall back to the document catalog
+	if _, ok := d.trailer["Root"]; !ok {
+		for _, num := range sortedKeys(d.objects) {
+			if dic, ok := d.objects[num].(dict); ok && dic["Type"] == name("Catalog") {
+				d.trailer["Root"] = ref{num: num}
+				break
+			}
+		}
+	}
+}
+
+func mergeTrailer(trailer, dic dict) {
+	for _, key := range []string{"Root", "Info", "Encrypt"} {
+		if v, ok := dic[key]; ok {
+			trailer[key] = v
+		}
+	}
+}
+
+// expandObjectStreams adds the objects packed into object streams (PDF 1.5
+// and later) that were not also written directly.
+func (d *Document) expandObjectStreams() {
+	for _, num := range sortedKeys(d.objects) {
+		s, ok := d.objects[num].(*stream)
+		if !ok || s.dict["Type"] != name("ObjStm") {
+			continue
+		}
+
+		data, err := d.decode(s)
+		if err != nil {
+			continue
+		}
+
+		n := d.int(s.dict["N"])
+		first := d.int(s.dict["First"])
+		if first < 0 || first > len(data) {
+			continue
+		}
+
+		header := &lexer{data: data[:first]}
+		for i := 0; i < n; i++ {
+			objNum, _ := header.next()
+			offset, _ := header.next()
+			on, ok1 := objNum.(float64)
+			off, ok2 := offset.(float64)
+			if !ok1 || !ok2 {
+				break
+			}
+			if _, exists := d.objects[int(on)]; exists {
+				continue
+			}
+			if off < 0 || first+int(off) >= len(data) {
+				continue
+			}
+
+			l := &lexer{data: data, pos: first + int(off)}
+			if obj, err := l.parseObject(); err == nil {
+				d.objects[int(on)] = obj
+			}
+		}
+	}
+}
+
+// resolve follows indirect references.
+func (d *Document) resolve(obj interface{}) interface{} {
+	for i := 0; i < 16; i++ {
+		r, ok := obj.(ref)
+		if !ok {
+			return obj
+		}
+		obj = d.objects[r.num]
+	}
+	return nil
+}
+
+func (d *Document) dict(obj interface{}) dict {
+	switch v := d.resolve(obj).(type) {
+	case dict:
+		return v
+	case *stream:
+		return v.dict
+	}
+	return nil
+}
+
+func (d *Document) int(obj interface{}) int {
+	if n, ok := d.resolve(obj).(float64); ok {
+		return int(n)
+	}
+	return 0
+}
+
+// text decodes a PDF text string, which is UTF-16BE when it starts with a
+// byte order mark and PDFDocEncoding (close to Latin-1) otherwise.
+func (d *Document) text(obj interface{}) string {
+	s, ok := d.resolve(obj).(pdfString)
+	if !ok {
+		return ""
+	}
+	return decodeText([]byte(s))
+}
+
+func decodeText(b []byte) string {
+	switch {
+	case len(b) >= 2 && b[0] == 0xFE && b[1] == 0xFF:
+		units := make([]uint16, 0, len(b)/2)
+		for i := 2; i+1 < len(b); i += 2 {
+			units = append(units, uint16(b[i])<<8|uint16(b[i+1]))
+		}
+		return string(utf16.Decode(units))
+	case len(b) >= 3 && b[0] == 0xEF && b[1] == 0xBB && b[2] == 0xBF:
+		return string(b[3:])
+	}
+
+	runes := make([]rune, len(b))
+	for i, c := range b {
+		runes[i] = rune(c)
+	}
+	return string(runes)
+}
+
+// decode applies a stream's filters to its data.
+func (d *Document) decode(s *stream) ([]byte, error) {
+	data := s.data
+
+	var filters []interface{}
+	switch f := d.resolve(s.dict["Filter"]).(type) {
+	case name:
+		filters = []interface{}{f}
+	case array:
+		filters = f
+	}
+
+	for _, f := range filters {
+		var err error
+		switch d.resolve(f) {
+		case name("FlateDecode"), name("Fl"):
+			data, err = inflate(data)
+			if err == nil && d.int(d.dict(s.dict["DecodeParms"])["Predictor"]) > 1 {
+				err = fmt.Errorf("unsupported predictor")
+			}
+		case name("ASCIIHexDecode"), name("AHx"):
+			data, err = hexDecode(data)
+		case name("ASCII85Decode"), name("A85"):
+			data, err = ascii85Decode(data)
+		default:
+			err = fmt.Errorf("unsupported filter %v", f)
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return data, nil
+}
+
+// inflate decompresses zlib data, keeping what could be read from a
+// truncated stream.
+func inflate(data []byte) ([]byte, error) {
+	var r io.ReadCloser
+	r, err := zlib.NewReader(bytes.NewReader(data))
+	if err != nil {
+		// Some writers omit the zlib header
+		r = flate.NewReader(bytes.NewReader(data))
+	}
+	defer r.Close()
+
+	out, err := io.ReadAll(r)
+	if err != nil && len(out) == 0 {
+		return nil, fmt.Errorf("failed to inflate stream: %w", err)
+	}
+	return out, nil
+}
+
+func hexDecode(data []byte) ([]byte, error) {
+	digits := []byte{}
+	for _, c := range data {
+		if c == '>' {
+			break
+		}
+		if !isSpace(c) {
+			digits = append(digits, c)
+		}
+	}
+	if len(digits)%2 == 1 {
+		digits = append(digits, '0')
+	}
+	out := make([]byte, len(digits)/2)
+	_, err := hex.Decode(out, digits)
+	return out, err
+}
+
+func ascii85Decode(data []byte) ([]byte, error) {
+	data = bytes.TrimSpace(data)
+	data = bytes.TrimPrefix(data, []byte("<~"))
+	if i := bytes.Index(data, []byte("~>")); i >= 0 {
+		data = data[:i]
+	}
+	out := make([]byte, 4*len(data))
+	n, _, err := ascii85.Decode(out, data, true)
+	return out[:n], err
+}
+
+// Info holds the document information dictionary.
+type Info struct {
+	Title    string
+	Author   string
+	Subject  string
+	Keywords string
+	Creator  string
+	Producer string
+	Created  time.Time
+}
+
+// Info returns the document information dictionary.
+func (d *Document) Info() Info {
+	info := d.dict(d.trailer["Info"])
+	if info == nil {
+		return Info{}
+	}
+
+	return Info{
+		Title:    d.text(info["Title"]),
+		Author:   d.text(info["Author"]),
+		Subject:  d.text(info["Subject"]),
+		Keywords: d.text(info["Keywords"]),
+		Creator:  d.text(info["Creator"]),
+		Producer: d.text(info["Producer"]),
+		Created:  parsePDFDate(d.text(info["CreationDate"])),
+	}
+}
+
+// parsePDFDate reads a date such as "D:20210303101500+01'00'", keeping as
+// much as is present.
+func parsePDFDate(s string) time.Time {
+	s = strings.TrimPrefix(s, "D:")
+	digits := 0
+	for digits < len(s) && s[digits] >= '0' && s[digits] <= '9' {
+		digits++
+	}
+
+	layouts := map[int]string{14: "20060102150405", 12: "200601021504", 10: "2006010215", 8: "20060102", 6: "200601", 4: "2006"}
+	if layout, ok := layouts[digits]; ok {
+		if t, err := time.Parse(layout, s[:digits]); err == nil {
+			return t
+		}
+	}
+	return time.Time{}
+}
+
+// XMP returns the raw XMP metadata packet of the document, if it has one.
+func (d *Document) XMP() []byte {
+	root := d.dict(d.trailer["Root"])
+	s, ok := d.resolve(root["Metadata"]).(*stream)
+	if !ok {
+		return nil
+	}
+
+	data, err := d.decode(s)
+	if err != nil {
+		return nil
+	}
+	return data
+}
+
+// Links returns the targets of the URI actions in the document, such as
+// the doi.org link many publishers put on the first page.
+func (d *Document) Links() []string {
+	links := []string{}
+	for _, num := range sortedKeys(d.objects) {
+		dic, ok := d.objects[num].(dict)
+		if !ok {
+			continue
+		}
+		action := d.dict(dic["A"])
+		if action == nil || action["S"] != name("URI") {
+			continue
+		}
+		if uri := d.text(action["URI"]); uri != "" {
+			links = append(links, uri)
+		}
+	}
+	return links
+}
+
+// pages returns the page dictionaries in document order, stopping after
+// limit pages.
+func (d *Document) pages(limit int) []dict {
+	root := d.dict(d.trailer["Root"])
+	if root == nil {
+		return nil
+	}
+
+	pages := []dict{}
+	seen := map[int]bool{}
+
+	var walk func(node interface{})
+	walk = func(node interface{}) {
+		if len(pages) >= limit {
+			return
+		}
+		if r, ok := node.(ref); ok {
+			if seen[r.num] {
+				return
+			}
+			seen[r.num] = true
+		}
+
+		dic := d.dict(node)
+		if dic == nil {
+			return
+		}
+
+		if kids, ok := d.resolve(dic["Kids"]).(array); ok {
+			for _, kid := range kids {
+				walk(kid)
+			}
+			return
+		}
+		pages = append(pages, dic)
+	}
+	walk(root["Pages"])
+
+	return pages
+}
+
+// contents returns the decoded content streams of a page, concatenated.
+func (d *Document) contents(page dict) []byte {
+	var parts []interface{}
+	switch c := d.resolve(page["Contents"]).(type) {
+	case array:
+		parts = c
+	case *stream:
+		parts = []interface{}{c}
+	}
+
+	var buf bytes.Buffer
+	for _, part := range parts {
+		s, ok := d.resolve(part).(*stream)
+		if !ok {
+			continue
+		}
+		if data, err := d.decode(s); err == nil {
+			buf.Write(data)
+			buf.WriteByte('\n')
+		}
+	}
+	return buf.Bytes()
+}
+
+func atoi(b []byte) int {
+	n, _ := strconv.Atoi(string(b))
+	return n
+}
+
+func sortedKeys(objects map[int]interface{}) []int {
+	keys := make([]int, 0, len(objects))
+	for k := range objects {
+		keys = append(keys, k)
+	}
+	sort.Ints(keys)
+	return keys
+}
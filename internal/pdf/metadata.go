@@ -0,0 +1,307 @@
+package pdf
+
+import (
+	"bytes"
+	"context"
+	"encoding/xml"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/knhn1004/bibtext-to-apa6/internal/bibtex"
+	"github.com/knhn1004/bibtext-to-apa6/internal/doi"
+	"github.com/knhn1004/bibtext-to-apa6/internal/url"
+)
+
+// textPages is how many leading pages are searched for identifiers; DOIs
+// and arXiv IDs are printed on the first page, and later pages hold the
+// reference list, whose DOIs belong to other works.
+const textPages = 2
+
+var (
+	arxivText = regexp.MustCompile(`(?i)arXiv:\s*(\d{4}\.\d{4,5}(?:v\d+)?|[a-z\-]+(?:\.[A-Z]{2})?/\d{7}(?:v\d+)?)`)
+
+	// Producer-generated titles such as "Microsoft Word - draft3.docx"
+	junkTitle = regexp.MustCompile(`(?i)^(?:microsoft word - .*|untitled.*|.*\.(?:docx?|pdf|tex|dvi|rtf|odt|indd))$`)
+)
+
+// Metadata is what a PDF says about the work it contains.
+type Metadata struct {
+	Title    string
+	Authors  []bibtex.Name
+	Date     time.Time
+	Subject  string
+	Keywords string
+	DOI      string
+	ArXiv    string
+}
+
+// ReadMetadata reads the metadata of the PDF file at path.
+func ReadMetadata(path string) (*Metadata, error) {
+	d, err := Open(path)
+	if err != nil {
+		return nil, err
+	}
+	return d.Metadata(), nil
+}
+
+// Metadata combines the XMP packet, which is preferred, with the Info
+// dictionary, and looks for a DOI or arXiv ID in the metadata, the links and
+// the text of the first pages.
+func (d *Document) Metadata() *Metadata {
+	info := d.Info()
+	x := parseXMP(d.XMP())
+
+	m := &Metadata{
+		Title:    firstNonEmpty(cleanTitle(x.title), cleanTitle(info.Title)),
+		Subject:  firstNonEmpty(x.description, info.Subject),
+		Keywords: info.Keywords,
+		Date:     info.Created,
+	}
+
+	if len(x.creators) > 0 {
+		for _, creator := range x.creators {
+			m.Authors = append(m.Authors, url.ParseByline(creator)...)
+		}
+	} else if info.Author != "" {
+		m.Authors = url.ParseByline(info.Author)
+	}
+
+	if date := parseXMPDate(x.date); !date.IsZero() {
+		m.Date = date
+	}
+
+	// Identifiers declared in the metadata come first, then links, then text
+	candidates := []string{x.doi}
+	candidates = append(candidates, x.identifiers...)
+	candidates = append(candidates, info.Subject, info.Keywords)
+	candidates = append(candidates, d.Links()...)
+
+	for _, c := range candidates {
+		if found := doi.Find(c); found != "" {
+			m.DOI = found
+			break
+		}
+	}
+
+	text := d.PageText(textPages)
+	if m.DOI == "" {
+		m.DOI = doi.Find(text)
+	}
+
+	for _, c := range append(candidates, text) {
+		if match := arxivText.FindStringSubmatch(c); match != nil {
+			m.ArXiv = match[1]
+			break
+		}
+	}
+
+	return m
+}
+
+// ToEntry builds a BibTeX entry from the PDF's own metadata, for use when its
+// identifiers cannot be resolved.
+func (m *Metadata) ToEntry() *bibtex.Entry {
+	entry := &bibtex.Entry{
+		Type:   "misc",
+		Fields: make(map[string]string),
+	}
+
+	set := func(field, value string) {
+		if value = strings.TrimSpace(value); value != "" {
+			entry.Fields[field] = value
+		}
+	}
+
+	set("author", bibtex.FormatNames(m.Authors))
+	set("title", m.Title)
+	if !m.Date.IsZero() {
+		set("year", strconv.Itoa(m.Date.Year()))
+	}
+	set("doi", m.DOI)
+	if m.ArXiv != "" {
+		set("eprint", m.ArXiv)
+		set("archiveprefix", "arXiv")
+		set("url", "https://arxiv.org/abs/"+m.ArXiv)
+	}
+	set("keywords", m.Keywords)
+
+	entry.Key = entry.GenerateKey("pdf")
+
+	return entry
+}
+
+// Reference builds a BibTeX entry for the PDF at path. When the PDF names a
+// DOI or arXiv ID and resolvers are given, the identifier is looked up;
+// otherwise, or if the lookup fails, the entry is built from the PDF's own
+// metadata.
+func Reference(ctx context.Context, path string, resolvers *url.Resolvers) (*bibtex.Entry, error) {
+	m, err := ReadMetadata(path)
+	if err != nil {
+		return nil, err
+	}
+
+	if resolvers != nil {
+		ids := []url.Identifier{}
+		if m.DOI != "" {
+			ids = append(ids, url.Identifier{Kind: url.IdentifierDOI, Value: m.DOI})
+		}
+		if m.ArXiv != "" {
+			ids = append(ids, url.Identifier{Kind: url.IdentifierArXiv, Value: m.ArXiv})
+		}
+
+		for _, id := range ids {
+			if entry, err := resolvers.Resolve(ctx, id); err == nil {
+				return entry, nil
+			}
+		}
+	}
+
+	// Untitled PDFs are at least recognizable by their file name
+	if m.Title == "" {
+		base := strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+		m.Title = strings.Join(strings.FieldsFunc(base, func(r rune) bool { return r == '_' || r == '-' || r == ' ' }), " ")
+	}
+
+	return m.ToEntry(), nil
+}
+
+// xmpData holds the XMP properties used for a reference.
+type xmpData struct {
+	title       string
+	description string
+	creators    []string
+	date        string
+	doi         string
+	identifiers []string
+}
+
+// parseXMP reads Dublin Core, PRISM and XMP basic properties from an XMP
+// packet. Properties may be elements, with rdf:Alt/rdf:Seq/rdf:Bag lists, or
+// attributes of rdf:Description.
+func parseXMP(data []byte) xmpData {
+	var x xmpData
+	if len(data) == 0 {
+		return x
+	}
+
+	var (
+		publicationDate string
+		createDate      string
+	)
+
+	set := func(property, value string) {
+		value = strings.TrimSpace(value)
+		if value == "" {
+			return
+		}
+
+		switch property {
+		case "title":
+			if x.title == "" {
+				x.title = value
+			}
+		case "description":
+			if x.description == "" {
+				x.description = value
+			}
+		case "creator":
+			x.creators = append(x.creators, value)
+		case "doi":
+			x.doi = value
+		case "identifier":
+			x.identifiers = append(x.identifiers, value)
+		case "coverDate", "publicationDate":
+			publicationDate = value
+		case "date":
+			if x.date == "" {
+				x.date = value
+			}
+		case "CreateDate":
+			createDate = value
+		}
+	}
+
+	decoder := xml.NewDecoder(bytes.NewReader(data))
+	decoder.Strict = false
+
+	stack := []string{}
+	for {
+		tok, err := decoder.Token()
+		if err != nil {
+			break
+		}
+
+		switch t := tok.(type) {
+		case xml.StartElement:
+			stack = append(stack, t.Name.Local)
+			if t.Name.Local == "Description" {
+				for _, attr := range t.Attr {
+					set(attr.Name.Local, attr.Value)
+				}
+			}
+		case xml.EndElement:
+			if len(stack) > 0 {
+				stack = stack[:len(stack)-1]
+			}
+		case xml.CharData:
+			if property := xmpProperty(stack); property != "" {
+				set(property, string(t))
+			}
+		}
+	}
+
+	switch {
+	case publicationDate != "":
+		x.date = publicationDate
+	case x.date == "":
+		x.date = createDate
+	}
+
+	return x
+}
+
+// xmpProperty returns the property whose value is the current text, skipping
+// the rdf:Alt/Seq/Bag/li containers.
+func xmpProperty(stack []string) string {
+	for i := len(stack) - 1; i >= 0; i-- {
+		switch stack[i] {
+		case "li", "Alt", "Seq", "Bag":
+			continue
+		case "Description", "RDF", "xmpmeta":
+			return ""
+		}
+		return stack[i]
+	}
+	return ""
+}
+
+// parseXMPDate reads an ISO 8601 date of any precision.
+func parseXMPDate(s string) time.Time {
+	for _, layout := range []string{time.RFC3339, "2006-01-02T15:04:05", "2006-01-02T15:04Z07:00", "2006-01-02", "2006-01", "2006"} {
+		if t, err := time.Parse(layout, strings.TrimSpace(s)); err == nil {
+			return t
+		}
+	}
+	return time.Time{}
+}
+
+// cleanTitle discards titles that are really file names.
+func cleanTitle(title string) string {
+	title = strings.Join(strings.Fields(title), " ")
+	if junkTitle.MatchString(title) {
+		return ""
+	}
+	return title
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, v := range values {
+		if v != "" {
+			return v
+		}
+	}
+	return ""
+}
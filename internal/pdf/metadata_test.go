@@ -0,0 +1,211 @@
+package pdf
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/knhn1004/bibtext-to-apa6/internal/bibtex"
+	"github.com/knhn1004/bibtext-to-apa6/internal/url"
+)
+
+func TestReadMetadata(t *testing.T) {
+	tests := []struct {
+		fixture string
+		want    *Metadata
+	}{
+		{
+			fixture: "info.pdf",
+			want: &Metadata{
+				Title:    "Sediment Transport in Braided Rivers",
+				Authors:  []bibtex.Name{{Family: "Smith", Given: "John"}, {Family: "Doe", Given: "Jane"}},
+				Date:     time.Date(2020, 3, 15, 9, 30, 0, 0, time.UTC),
+				Keywords: "rivers, sediment",
+				DOI:      "10.5555/example.2020.001",
+			},
+		},
+		{
+			// XMP wins over an Info dictionary written by a word processor
+			fixture: "xmp.pdf",
+			want: &Metadata{
+				Title:   "Attention in Río Negro Basins",
+				Authors: []bibtex.Name{{Family: "García", Given: "María"}, {Family: "Chen", Given: "Wei"}},
+				Date:    time.Date(2023, 1, 5, 10, 0, 0, 0, time.UTC),
+				ArXiv:   "2301.01234v2",
+			},
+		},
+		{
+			fixture: "objstm.pdf",
+			want: &Metadata{
+				Title:   "Unicode Titel",
+				Authors: []bibtex.Name{{Family: "Lovelace", Given: "Ada"}},
+				Subject: "doi:10.5555/objstm.7",
+				DOI:     "10.5555/objstm.7",
+			},
+		},
+		{
+			// Object streams with out-of-range /First and offsets are skipped
+			fixture: "malformed-objstm.pdf",
+			want:    &Metadata{Title: "Damaged Object Streams"},
+		},
+		{
+			// The Info dictionary was lost, but the partial first page
+			// still has the DOI
+			fixture: "truncated.pdf",
+			want:    &Metadata{DOI: "10.5555/example.2020.001"},
+		},
+		{
+			fixture: "field_notes-2021.pdf",
+			want:    &Metadata{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.fixture, func(t *testing.T) {
+			got, err := ReadMetadata(filepath.Join("testdata", tt.fixture))
+			if err != nil {
+				t.Fatalf("ReadMetadata() error = %v", err)
+			}
+			if !got.Date.Equal(tt.want.Date) {
+				t.Errorf("Date = %v, want %v", got.Date, tt.want.Date)
+			}
+			got.Date, tt.want.Date = time.Time{}, time.Time{}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ReadMetadata() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestReadMetadataErrors(t *testing.T) {
+	tests := []struct {
+		fixture string
+		wantErr error
+	}{
+		{"encrypted.pdf", ErrEncrypted},
+		{"header-only.pdf", nil},
+		{"not-a-pdf.pdf", nil},
+		{"missing.pdf", nil},
+	}
+
+	for _, tt := range tests {
+		_, err := ReadMetadata(filepath.Join("testdata", tt.fixture))
+		if err == nil {
+			t.Errorf("ReadMetadata(%s) succeeded, want an error", tt.fixture)
+		}
+		if tt.wantErr != nil && !errors.Is(err, tt.wantErr) {
+			t.Errorf("ReadMetadata(%s) error = %v, want %v", tt.fixture, err, tt.wantErr)
+		}
+	}
+}
+
+func TestReference(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/10.5555/example.2020.001" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Content-Type", "application/vnd.citationstyles.csl+json")
+		w.Write([]byte(`{
+			"type": "article-journal",
+			"title": "Sediment transport in braided rivers",
+			"container-title": "Journal of Examples",
+			"author": [{"family": "Smith", "given": "John"}, {"family": "Doe", "given": "Jane"}],
+			"issued": {"date-parts": [[2020, 3]]},
+			"volume": "12",
+			"page": "1-10",
+			"DOI": "10.5555/example.2020.001"
+		}`))
+	}))
+	defer server.Close()
+
+	resolvers := &url.Resolvers{DOI: &url.DOIResolver{BaseURL: server.URL}}
+
+	tests := []struct {
+		name      string
+		fixture   string
+		resolvers *url.Resolvers
+		wantType  string
+		want      map[string]string
+	}{
+		{
+			name:      "resolved DOI",
+			fixture:   "info.pdf",
+			resolvers: resolvers,
+			wantType:  "article",
+			want: map[string]string{
+				"author":  "Smith, John and Doe, Jane",
+				"title":   "Sediment transport in braided rivers",
+				"journal": "Journal of Examples",
+				"year":    "2020",
+				"volume":  "12",
+				"pages":   "1-10",
+				"doi":     "10.5555/example.2020.001",
+			},
+		},
+		{
+			name:     "no resolvers",
+			fixture:  "info.pdf",
+			wantType: "misc",
+			want: map[string]string{
+				"author":   "Smith, John and Doe, Jane",
+				"title":    "Sediment Transport in Braided Rivers",
+				"year":     "2020",
+				"doi":      "10.5555/example.2020.001",
+				"keywords": "rivers, sediment",
+			},
+		},
+		{
+			// The DOI is unknown to the resolver, and no arXiv resolver
+			// is configured, so the PDF's own metadata is used
+			name:      "unresolved identifiers",
+			fixture:   "objstm.pdf",
+			resolvers: resolvers,
+			wantType:  "misc",
+			want: map[string]string{
+				"author": "Lovelace, Ada",
+				"title":  "Unicode Titel",
+				"doi":    "10.5555/objstm.7",
+			},
+		},
+		{
+			name:     "arXiv ID",
+			fixture:  "xmp.pdf",
+			wantType: "misc",
+			want: map[string]string{
+				"author":        "García, María and Chen, Wei",
+				"title":         "Attention in Río Negro Basins",
+				"year":          "2023",
+				"eprint":        "2301.01234v2",
+				"archiveprefix": "arXiv",
+				"url":           "https://arxiv.org/abs/2301.01234v2",
+			},
+		},
+		{
+			name:     "title from file name",
+			fixture:  "field_notes-2021.pdf",
+			wantType: "misc",
+			want:     map[string]string{"title": "field notes 2021"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			entry, err := Reference(context.Background(), filepath.Join("testdata", tt.fixture), tt.resolvers)
+			if err != nil {
+				t.Fatalf("Reference() error = %v", err)
+			}
+			if entry.Type != tt.wantType {
+				t.Errorf("Type = %q, want %q", entry.Type, tt.wantType)
+			}
+			if !reflect.DeepEqual(entry.Fields, tt.want) {
+				t.Errorf("Fields = %v, want %v", entry.Fields, tt.want)
+			}
+		})
+	}
+}
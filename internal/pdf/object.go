@@ -0,0 +1,313 @@
+package pdf
+
+import (
+	"bytes"
+	"fmt"
+	"strconv"
+)
+
+// PDF objects are represented with Go values: nil, bool, float64, name,
+// pdfString, array, dict, ref and *stream.
+type (
+	name      string
+	pdfString string
+	array     []interface{}
+	dict      map[string]interface{}
+	keyword   string
+)
+
+type ref struct {
+	num int
+	gen int
+}
+
+// stream is a dictionary followed by (possibly encoded) data.
+type stream struct {
+	dict dict
+	data []byte
+}
+
+// Delimiter tokens returned by the lexer.
+const (
+	tokDictStart  keyword = "<<"
+	tokDictEnd    keyword = ">>"
+	tokArrayStart keyword = "["
+	tokArrayEnd   keyword = "]"
+)
+
+// lexer reads tokens from PDF object syntax and content streams.
+type lexer struct {
+	data []byte
+	pos  int
+}
+
+func isSpace(c byte) bool {
+	return c == 0 || c == '\t' || c == '\n' || c == '\f' || c == '\r' || c == ' '
+}
+
+func isDelimiter(c byte) bool {
+	return bytes.IndexByte([]byte("()<>[]{}/%"), c) >= 0
+}
+
+func (l *lexer) skipSpace() {
+	for l.pos < len(l.data) {
+		c := l.data[l.pos]
+		switch {
+		case isSpace(c):
+			l.pos++
+		case c == '%':
+			for l.pos < len(l.data) && l.data[l.pos] != '\n' && l.data[l.pos] != '\r' {
+				l.pos++
+			}
+		default:
+			return
+		}
+	}
+}
+
+// next returns the next token: a keyword (including delimiters and
+// operators), a number, a name or a string. It returns nil at the end of
+// the data.
+func (l *lexer) next() (interface{}, error) {
+	l.skipSpace()
+	if l.pos >= len(l.data) {
+		return nil, nil
+	}
+
+	c := l.data[l.pos]
+	switch {
+	case c == '/':
+		return l.readName(), nil
+	case c == '(':
+		return l.readLiteral()
+	case c == '<':
+		if l.pos+1 < len(l.data) && l.data[l.pos+1] == '<' {
+			l.pos += 2
+			return tokDictStart, nil
+		}
+		return l.readHex()
+	case c == '>':
+		if l.pos+1 < len(l.data) && l.data[l.pos+1] == '>' {
+			l.pos += 2
+			return tokDictEnd, nil
+		}
+		l.pos++
+		return nil, fmt.Errorf("unexpected '>' at offset %d", l.pos-1)
+	case c == '[' || c == ']' || c == '{' || c == '}':
+		l.pos++
+		return keyword(c), nil
+	case c == ')':
+		l.pos++
+		return nil, fmt.Errorf("unexpected ')' at offset %d", l.pos-1)
+	}
+
+	start := l.pos
+	for l.pos < len(l.data) && !isSpace(l.data[l.pos]) && !isDelimiter(l.data[l.pos]) {
+		l.pos++
+	}
+	word := string(l.data[start:l.pos])
+
+	if c == '+' || c == '-' || c == '.' || (c >= '0' && c <= '9') {
+		if n, err := strconv.ParseFloat(word, 64); err == nil {
+			return n, nil
+		}
+	}
+
+	return keyword(word), nil
+}
+
+func (l *lexer) readName() name {
+	l.pos++ // skip '/'
+	var b bytes.Buffer
+	for l.pos < len(l.data) && !isSpace(l.data[l.pos]) && !isDelimiter(l.data[l.pos]) {
+		c := l.data[l.pos]
+		if c == '#' && l.pos+2 < len(l.data) {
+			if v, err := strconv.ParseUint(string(l.data[l.pos+1:l.pos+3]), 16, 8); err == nil {
+				b.WriteByte(byte(v))
+				l.pos += 3
+				continue
+			}
+		}
+		b.WriteByte(c)
+		l.pos++
+	}
+	return name(b.String())
+}
+
+func (l *lexer) readLiteral() (pdfString, error) {
+	l.pos++ // skip '('
+	var b bytes.Buffer
+	depth := 1
+
+	for l.pos < len(l.data) {
+		c := l.data[l.pos]
+		l.pos++
+
+		switch c {
+		case '(':
+			depth++
+		case ')':
+			depth--
+			if depth == 0 {
+				return pdfString(b.String()), nil
+			}
+		case '\\':
+			if l.pos >= len(l.data) {
+				continue
+			}
+			e := l.data[l.pos]
+			l.pos++
+			switch e {
+			case 'n':
+				b.WriteByte('\n')
+			case 'r':
+				b.WriteByte('\r')
+			case 't':
+				b.WriteByte('\t')
+			case 'b':
+				b.WriteByte('\b')
+			case 'f':
+				b.WriteByte('\f')
+			case '\r':
+				// Line continuation
+				if l.pos < len(l.data) && l.data[l.pos] == '\n' {
+					l.pos++
+				}
+			case '\n':
+			default:
+				if e >= '0' && e <= '7' {
+					v := int(e - '0')
+					for i := 0; i < 2 && l.pos < len(l.data) && l.data[l.pos] >= '0' && l.data[l.pos] <= '7'; i++ {
+						v = v*8 + int(l.data[l.pos]-'0')
+						l.pos++
+					}
+					b.WriteByte(byte(v))
+				} else {
+					b.WriteByte(e)
+				}
+			}
+			continue
+		}
+
+		b.WriteByte(c)
+	}
+
+	return "", fmt.Errorf("unterminated string")
+}
+
+func (l *lexer) readHex() (pdfString, error) {
+	l.pos++ // skip '<'
+	var digits []byte
+
+	for l.pos < len(l.data) {
+		c := l.data[l.pos]
+		l.pos++
+		if c == '>' {
+			if len(digits)%2 == 1 {
+				digits = append(digits, '0')
+			}
+			out := make([]byte, len(digits)/2)
+			for i := range out {
+				v, _ := strconv.ParseUint(string(digits[2*i:2*i+2]), 16, 8)
+				out[i] = byte(v)
+			}
+			return pdfString(out), nil
+		}
+		if isSpace(c) {
+			continue
+		}
+		digits = append(digits, c)
+	}
+
+	return "", fmt.Errorf("unterminated hex string")
+}
+
+// parseObject reads one object, including arrays, dictionaries and
+// indirect references ("12 0 R").
+func (l *lexer) parseObject() (interface{}, error) {
+	tok, err := l.next()
+	if err != nil {
+		return nil, err
+	}
+	return l.parseFrom(tok)
+}
+
+func (l *lexer) parseFrom(tok interface{}) (interface{}, error) {
+	switch t := tok.(type) {
+	case keyword:
+		switch t {
+		case tokDictStart:
+			return l.parseDict()
+		case tokArrayStart:
+			return l.parseArray()
+		case "true":
+			return true, nil
+		case "false":
+			return false, nil
+		case "null":
+			return nil, nil
+		}
+		return t, nil
+	case float64:
+		// "num gen R" is a reference
+		save := l.pos
+		gen, _ := l.next()
+		if g, ok := gen.(float64); ok {
+			if r, _ := l.next(); r == keyword("R") {
+				return ref{num: int(t), gen: int(g)}, nil
+			}
+		}
+		l.pos = save
+		return t, nil
+	}
+	return tok, nil
+}
+
+func (l *lexer) parseArray() (array, error) {
+	arr := array{}
+	for {
+		tok, err := l.next()
+		if err != nil {
+			return nil, err
+		}
+		if tok == nil {
+			return nil, fmt.Errorf("unterminated array")
+		}
+		if tok == tokArrayEnd {
+			return arr, nil
+		}
+
+		obj, err := l.parseFrom(tok)
+		if err != nil {
+			return nil, err
+		}
+		arr = append(arr, obj)
+	}
+}
+
+func (l *lexer) parseDict() (dict, error) {
+	d := dict{}
+	for {
+		tok, err := l.next()
+		if err != nil {
+			return nil, err
+		}
+		if tok == nil {
+			return nil, fmt.Errorf("unterminated dictionary")
+		}
+		if tok == tokDictEnd {
+			return d, nil
+		}
+
+		key, ok := tok.(name)
+		if !ok {
+			return nil, fmt.Errorf("dictionary key is not a name at offset %d", l.pos)
+		}
+
+		value, err := l.parseObject()
+		if err != nil {
+			return nil, err
+		}
+		d[string(key)] = value
+	}
+}
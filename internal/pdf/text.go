@@ -0,0 +1,107 @@
+package pdf
+
+import (
+	"bytes"
+	"strings"
+)
+
+// PageText returns the text of the first n pages. Text is taken from the
+// string operands of the text-showing operators, so it is only readable for
+// fonts with a standard encoding; it is meant for finding identifiers, not
+// for reproducing the layout.
+func (d *Document) PageText(n int) string {
+	var b strings.Builder
+	for _, page := range d.pages(n) {
+		b.WriteString(contentText(d.contents(page)))
+		b.WriteString("\n\n")
+	}
+	return b.String()
+}
+
+// contentText extracts the shown strings from a content stream.
+func contentText(content []byte) string {
+	var b strings.Builder
+	l := &lexer{data: content}
+	operands := []interface{}{}
+
+	for {
+		tok, err := l.next()
+		if err != nil {
+			continue
+		}
+		if tok == nil {
+			break
+		}
+
+		op, isOp := tok.(keyword)
+		if !isOp || op == tokArrayStart || op == tokDictStart {
+			obj, err := l.parseFrom(tok)
+			if err == nil {
+				operands = append(operands, obj)
+			}
+			continue
+		}
+
+		switch op {
+		case "Tj", "'", "\"":
+			if op != "Tj" {
+				b.WriteByte('\n')
+			}
+			if len(operands) > 0 {
+				if s, ok := operands[len(operands)-1].(pdfString); ok {
+					b.WriteString(showText(s))
+				}
+			}
+		case "TJ":
+			if len(operands) > 0 {
+				if arr, ok := operands[len(operands)-1].(array); ok {
+					for _, item := range arr {
+						switch v := item.(type) {
+						case pdfString:
+							b.WriteString(showText(v))
+						case float64:
+							// A large negative adjustment is a word gap
+							if v < -200 {
+								b.WriteByte(' ')
+							}
+						}
+					}
+				}
+			}
+		case "Td", "TD", "Tm", "T*":
+			b.WriteByte('\n')
+		case "ET":
+			b.WriteByte('\n')
+		case "BI":
+			skipInlineImage(l)
+		}
+
+		operands = operands[:0]
+	}
+
+	return b.String()
+}
+
+// showText decodes the bytes of a shown string. Two-byte CID strings come out
+// as noise, which is harmless for identifier matching.
+func showText(s pdfString) string {
+	return decodeText([]byte(s))
+}
+
+// skipInlineImage moves past the binary data of an inline image, which
+// runs from the ID operator to EI.
+func skipInlineImage(l *lexer) {
+	i := bytes.Index(l.data[l.pos:], []byte("ID"))
+	if i < 0 {
+		l.pos = len(l.data)
+		return
+	}
+	l.pos += i + 2
+
+	j := bytes.Index(l.data[l.pos:], []byte("EI"))
+	if j < 0 {
+		l.pos = len(l.data)
+		return
+	}
+	l.pos += j + 2
+}
@@ -0,0 +1,193 @@
+// Package refresh extracts the metadata of URL references again from the
+// page snapshots stored with them, so that extractor improvements can be
+// applied to the pages the references were made from.
+package refresh
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+
+	"github.com/knhn1004/bibtext-to-apa6/internal/apa"
+	"github.com/knhn1004/bibtext-to-apa6/internal/bibtex"
+	"github.com/knhn1004/bibtext-to-apa6/internal/db"
+	"github.com/knhn1004/bibtext-to-apa6/internal/url"
+)
+
+// ErrNoSnapshot is returned for references stored without a page snapshot.
+var ErrNoSnapshot = errors.New("no snapshot stored for reference")
+
+// Change is a field whose value differs after re-extraction. An empty Old or
+// New means the field was added or removed.
+type Change struct {
+	Field string
+	Old   string
+	New   string
+}
+
+// Result is the re-extracted form of a reference.
+type Result struct {
+	ReferenceID  int
+	ReferenceNum int
+	URL          string
+	Entry        *bibtex.Entry
+	APAFormat    string
+	OldAPAFormat string
+	Changes      []Change
+	Err          error
+}
+
+// FromSnapshot extracts the metadata of ref again from its stored snapshot
+// and compares the result with the stored entry. The citation key and
+// retrieval date of the stored entry are kept.
+func FromSnapshot(database *db.DB, ref *db.Reference, loc *apa.Locale) (*Result, error) {
+	snapshot, err := database.GetSnapshot(ref.ID)
+	if err != nil {
+		return nil, err
+	}
+	if snapshot == nil {
+		return nil, ErrNoSnapshot
+	}
+
+	page := &url.Page{
+		URL:        snapshot.URL,
+		StatusCode: http.StatusOK,
+		Header:     http.Header{},
+		Body:       snapshot.HTML,
+	}
+	if snapshot.ContentType != "" {
+		page.Header.Set("Content-Type", snapshot.ContentType)
+	}
+
+	metadata, err := url.ParsePage(page, snapshot.URL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse snapshot: %w", err)
+	}
+	metadata.AccessDate = snapshot.FetchedAt
+
+	entry := metadata.ToEntry()
+
+	old, err := bibtex.Parse(ref.BibtexEntry)
+	if err != nil {
+		// References stored without an entry are compared field by field
+		// against nothing
+		old = &bibtex.Entry{Fields: map[string]string{}}
+	}
+	if old.Key != "" {
+		entry.Key = old.Key
+	}
+	if urldate := old.GetField("urldate"); urldate != "" {
+		entry.Fields["urldate"] = urldate
+	}
+
+	apaFormat, err := apa.FormatLocale(entry, loc)
+	if err != nil {
+		return nil, fmt.Errorf("failed to format reference: %w", err)
+	}
+
+	return &Result{
+		ReferenceID:  ref.ID,
+		ReferenceNum: ref.ReferenceNum,
+		URL:          snapshot.URL,
+		Entry:        entry,
+		APAFormat:    apaFormat,
+		OldAPAFormat: ref.APAFormat,
+		Changes:      Diff(old, entry),
+	}, nil
+}
+
+// Run re-extracts every reference that has a snapshot. References without
+// one are skipped; other failures are reported in the result's Err.
+func Run(database *db.DB, refs []*db.Reference, loc *apa.Locale) []Result {
+	results := []Result{}
+	for _, ref := range refs {
+		result, err := FromSnapshot(database, ref, loc)
+		if errors.Is(err, ErrNoSnapshot) {
+			continue
+		}
+		if err != nil {
+			results = append(results, Result{ReferenceID: ref.ID, ReferenceNum: ref.ReferenceNum, Err: err})
+			continue
+		}
+		results = append(results, *result)
+	}
+	return results
+}
+
+// Diff returns the fields that differ between two entries, with the entry
+// type first and the rest in alphabetical order.
+func Diff(old, new *bibtex.Entry) []Change {
+	changes := []Change{}
+	if old.Type != new.Type {
+		changes = append(changes, Change{Field: "type", Old: old.Type, New: new.Type})
+	}
+
+	fields := map[string]bool{}
+	for field := range old.Fields {
+		fields[field] = true
+	}
+	for field := range new.Fields {
+		fields[field] = true
+	}
+
+	names := make([]string, 0, len(fields))
+	for field := range fields {
+		names = append(names, field)
+	}
+	sort.Strings(names)
+
+	for _, field := range names {
+		if before, after := old.GetField(field), new.GetField(field); before != after {
+			changes = append(changes, Change{Field: field, Old: before, New: after})
+		}
+	}
+	return changes
+}
+
+// Apply replaces the stored reference with its re-extracted form.
+func Apply(database *db.DB, result Result) error {
+	if result.Err != nil || result.Entry == nil {
+		return fmt.Errorf("reference %d was not re-extracted", result.ReferenceNum)
+	}
+	return database.UpdateReference(result.ReferenceID, result.Entry.String(), result.APAFormat)
+}
+
+// WriteDiff writes the changes of each result as removed and added lines,
+// followed by the old and new APA references. Unchanged references are
+// listed on one line.
+func WriteDiff(w io.Writer, results []Result) error {
+	// A bufio.Writer keeps the first write error, which Flush returns
+	bw := bufio.NewWriter(w)
+
+	for _, r := range results {
+		if r.Err != nil {
+			fmt.Fprintf(bw, "[%d] error: %v\n", r.ReferenceNum, r.Err)
+			continue
+		}
+
+		if len(r.Changes) == 0 {
+			fmt.Fprintf(bw, "[%d] %s: unchanged\n", r.ReferenceNum, r.URL)
+			continue
+		}
+
+		fmt.Fprintf(bw, "[%d] %s\n", r.ReferenceNum, r.URL)
+		for _, c := range r.Changes {
+			if c.Old != "" {
+				fmt.Fprintf(bw, "  - %s: %s\n", c.Field, c.Old)
+			}
+			if c.New != "" {
+				fmt.Fprintf(bw, "  + %s: %s\n", c.Field, c.New)
+			}
+		}
+		if r.APAFormat != r.OldAPAFormat {
+			fmt.Fprintf(bw, "  - %s\n", r.OldAPAFormat)
+			fmt.Fprintf(bw, "  + %s\n", r.APAFormat)
+		}
+		fmt.Fprintln(bw)
+	}
+
+	return bw.Flush()
+}
@@ -0,0 +1,269 @@
+package refresh
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/knhn1004/bibtext-to-apa6/internal/bibtex"
+	"github.com/knhn1004/bibtext-to-apa6/internal/db"
+)
+
+func openTestDB(t *testing.T) *db.DB {
+	t.Helper()
+
+	database, err := db.NewDB(filepath.Join(t.TempDir(), "refs.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { database.Close() })
+
+	if err := database.Migrate(); err != nil {
+		t.Fatal(err)
+	}
+	return database
+}
+
+func TestFromSnapshotCharset(t *testing.T) {
+	database := openTestDB(t)
+
+	project, err := database.CreateProject("test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	ref, err := database.AddReference(project.ID, "", "Old citation.", "url")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// A UTF-8 page served without a charset, with its title past the
+	// 1024 bytes the charset sniffer reads
+	html, err := os.ReadFile(filepath.Join("..", "url", "testdata", "charset", "undeclared-utf8.html"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = database.SaveSnapshot(&db.Snapshot{
+		ReferenceID: ref.ID,
+		URL:         "https://example.com/cafe",
+		ContentType: "text/html",
+		HTML:        html,
+		FetchedAt:   time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	result, err := FromSnapshot(database, ref, nil)
+	if err != nil {
+		t.Fatalf("FromSnapshot() error = %v", err)
+	}
+	if title := result.Entry.GetField("title"); title != "Café Müller" {
+		t.Errorf("title = %q, want Café Müller", title)
+	}
+	if !strings.Contains(result.APAFormat, "*Café müller*") {
+		t.Errorf("APAFormat = %q, want the title decoded as UTF-8", result.APAFormat)
+	}
+}
+
+// addArticle stores a reference to the article fixture of the url package,
+// with an out-of-date entry and, if snapshot is set, the page it came from.
+func addArticle(t *testing.T, database *db.DB, projectID int, apaFormat string, snapshot bool) *db.Reference {
+	t.Helper()
+
+	entry := "@misc{lovelace2022,\n  author = {Lovelace, Ada},\n  title = {Rivers},\n  year = {2022},\n  urldate = {2024-04-01}\n}"
+	ref, err := database.AddReference(projectID, entry, apaFormat, "url")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !snapshot {
+		return ref
+	}
+
+	html, err := os.ReadFile(filepath.Join("..", "url", "testdata", "pages", "article.html"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = database.SaveSnapshot(&db.Snapshot{
+		ReferenceID: ref.ID,
+		URL:         "https://fieldnotes.example.com/articles/rivers",
+		ContentType: "text/html; charset=utf-8",
+		HTML:        html,
+		FetchedAt:   time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return ref
+}
+
+func TestRunAndApply(t *testing.T) {
+	database := openTestDB(t)
+
+	project, err := database.CreateProject("test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	withSnapshot := addArticle(t, database, project.ID, "Lovelace, A. (2022). Rivers.", true)
+	addArticle(t, database, project.ID, "Lovelace, A. (2022). Rivers without a snapshot.", false)
+
+	refs, err := database.ListReferences(project.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// References without a snapshot are skipped
+	results := Run(database, refs, nil)
+	if len(results) != 1 || results[0].ReferenceID != withSnapshot.ID {
+		t.Fatalf("Run() = %+v, want one result for reference %d", results, withSnapshot.ID)
+	}
+	result := results[0]
+	if result.Err != nil {
+		t.Fatalf("Run() error = %v", result.Err)
+	}
+
+	// The key and retrieval date of the stored entry are kept
+	if result.Entry.Key != "lovelace2022" || result.Entry.GetField("urldate") != "2024-04-01" {
+		t.Errorf("Entry = %s, want the stored key and urldate", result.Entry)
+	}
+	want := "Lovelace, A. (2022, September 14). *Rivers of the pacific northwest*. Field Notes. Retrieved from https://fieldnotes.example.com/articles/rivers"
+	if result.APAFormat != want {
+		t.Errorf("APAFormat =\n%s\nwant\n%s", result.APAFormat, want)
+	}
+
+	changed := map[string]bool{}
+	for _, c := range result.Changes {
+		changed[c.Field] = true
+	}
+	for _, field := range []string{"type", "day", "month", "organization", "title", "url"} {
+		if !changed[field] {
+			t.Errorf("Changes = %+v, want a change to %s", result.Changes, field)
+		}
+	}
+	for _, field := range []string{"author", "year", "urldate"} {
+		if changed[field] {
+			t.Errorf("Changes = %+v, want %s unchanged", result.Changes, field)
+		}
+	}
+
+	if err := Apply(database, result); err != nil {
+		t.Fatalf("Apply() error = %v", err)
+	}
+	ref, err := database.GetReference(withSnapshot.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ref.APAFormat != result.APAFormat || ref.BibtexEntry != result.Entry.String() {
+		t.Errorf("stored reference = %q, %q, want the re-extracted one", ref.APAFormat, ref.BibtexEntry)
+	}
+
+	// Once applied, there is nothing left to change
+	again, err := FromSnapshot(database, ref, nil)
+	if err != nil {
+		t.Fatalf("FromSnapshot() error = %v", err)
+	}
+	if len(again.Changes) != 0 {
+		t.Errorf("Changes after Apply = %+v, want none", again.Changes)
+	}
+
+	if err := Apply(database, Result{ReferenceNum: 3, Err: errors.New("failed")}); err == nil {
+		t.Errorf("Apply() of a failed result succeeded")
+	}
+}
+
+func TestFromSnapshotMissing(t *testing.T) {
+	database := openTestDB(t)
+
+	project, err := database.CreateProject("test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	ref := addArticle(t, database, project.ID, "Lovelace, A. (2022). Rivers.", false)
+
+	if _, err := FromSnapshot(database, ref, nil); !errors.Is(err, ErrNoSnapshot) {
+		t.Errorf("FromSnapshot() error = %v, want ErrNoSnapshot", err)
+	}
+}
+
+func TestDiff(t *testing.T) {
+	old := &bibtex.Entry{Type: "misc", Fields: map[string]string{
+		"author": "Doe, Jane",
+		"title":  "Rivers",
+		"year":   "2020",
+		"note":   "Draft",
+	}}
+	new := &bibtex.Entry{Type: "online", Fields: map[string]string{
+		"author": "Doe, Jane",
+		"title":  "Rivers of the world",
+		"year":   "2020",
+		"url":    "https://example.com/rivers",
+	}}
+
+	want := []Change{
+		{Field: "type", Old: "misc", New: "online"},
+		{Field: "note", Old: "Draft"},
+		{Field: "title", Old: "Rivers", New: "Rivers of the world"},
+		{Field: "url", New: "https://example.com/rivers"},
+	}
+	if got := Diff(old, new); !reflect.DeepEqual(got, want) {
+		t.Errorf("Diff() = %+v, want %+v", got, want)
+	}
+
+	if got := Diff(old, old); len(got) != 0 {
+		t.Errorf("Diff() of an entry with itself = %+v, want none", got)
+	}
+}
+
+func TestWriteDiff(t *testing.T) {
+	results := []Result{
+		{
+			ReferenceNum: 1,
+			URL:          "https://example.com/rivers",
+			Changes: []Change{
+				{Field: "title", Old: "Rivers", New: "Rivers of the world"},
+				{Field: "note", Old: "Draft"},
+				{Field: "url", New: "https://example.com/rivers"},
+			},
+			OldAPAFormat: "Doe, J. (2020). Rivers.",
+			APAFormat:    "Doe, J. (2020). Rivers of the world.",
+		},
+		{ReferenceNum: 2, URL: "https://example.com/lakes"},
+		{ReferenceNum: 3, Err: errors.New("failed to parse snapshot")},
+	}
+
+	want := "[1] https://example.com/rivers\n" +
+		"  - title: Rivers\n" +
+		"  + title: Rivers of the world\n" +
+		"  - note: Draft\n" +
+		"  + url: https://example.com/rivers\n" +
+		"  - Doe, J. (2020). Rivers.\n" +
+		"  + Doe, J. (2020). Rivers of the world.\n" +
+		"\n" +
+		"[2] https://example.com/lakes: unchanged\n" +
+		"[3] error: failed to parse snapshot\n"
+
+	var out strings.Builder
+	if err := WriteDiff(&out, results); err != nil {
+		t.Fatalf("WriteDiff() error = %v", err)
+	}
+	if out.String() != want {
+		t.Errorf("WriteDiff() =\n%s\nwant\n%s", out.String(), want)
+	}
+
+	errWrite := errors.New("disk full")
+	if err := WriteDiff(failingWriter{errWrite}, results); !errors.Is(err, errWrite) {
+		t.Errorf("WriteDiff() to a failing writer error = %v, want %v", err, errWrite)
+	}
+}
+
+// failingWriter is an io.Writer that always fails.
+type failingWriter struct {
+	err error
+}
+
+func (w failingWriter) Write(p []byte) (int, error) {
+	return 0, w.err
+}
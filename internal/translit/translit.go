@@ -0,0 +1,146 @@
+package translit
+
+import (
+	"strings"
+	"unicode"
+
+	"github.com/knhn1004/bibtext-to-apa6/internal/bibtex"
+)
+
+// cyrillic follows the BGN/PCGN romanization of Russian, extended with the
+// Ukrainian, Belarusian and Serbian letters that Russian lacks.
+var cyrillic = map[rune]string{
+	'а': "a", 'б': "b", 'в': "v", 'г': "g", 'д': "d", 'е': "e", 'ё': "yo",
+	'ж': "zh", 'з': "z", 'и': "i", 'й': "y", 'к': "k", 'л': "l", 'м': "m",
+	'н': "n", 'о': "o", 'п': "p", 'р': "r", 'с': "s", 'т': "t", 'у': "u",
+	'ф': "f", 'х': "kh", 'ц': "ts", 'ч': "ch", 'ш': "sh", 'щ': "shch",
+	'ъ': "", 'ы': "y", 'ь': "", 'э': "e", 'ю': "yu", 'я': "ya",
+	'є': "ye", 'і': "i", 'ї': "yi", 'ґ': "g", 'ў': "w",
+	'ђ': "dj", 'ј': "j", 'љ': "lj", 'њ': "nj", 'ћ': "c", 'џ': "dz",
+}
+
+// greek follows ELOT 743 for single letters; digraphs are handled in
+// romanizeGreek.
+var greek = map[rune]string{
+	'α': "a", 'β': "v", 'γ': "g", 'δ': "d", 'ε': "e", 'ζ': "z", 'η': "i",
+	'θ': "th", 'ι': "i", 'κ': "k", 'λ': "l", 'μ': "m", 'ν': "n", 'ξ': "x",
+	'ο': "o", 'π': "p", 'ρ': "r", 'σ': "s", 'ς': "s", 'τ': "t", 'υ': "y",
+	'φ': "f", 'χ': "ch", 'ψ': "ps", 'ω': "o",
+	'ά': "a", 'έ': "e", 'ή': "i", 'ί': "i", 'ό': "o", 'ύ': "y", 'ώ': "o",
+	'ϊ': "i", 'ϋ': "y", 'ΐ': "i", 'ΰ': "y",
+}
+
+// greekVoiced are the letters after which αυ/ευ are pronounced av/ev.
+const greekVoiced = "αεηιοωυάέήίόύώβγδζλμνρ"
+
+// Romanize transliterates the Cyrillic and Greek letters in s into Latin
+// script. Other characters are left unchanged.
+func Romanize(s string) string {
+	runes := []rune(s)
+	var b strings.Builder
+
+	for i := 0; i < len(runes); i++ {
+		r := runes[i]
+		lower := unicode.ToLower(r)
+
+		if latin, ok := cyrillic[lower]; ok {
+			b.WriteString(matchCase(latin, runes, i))
+			continue
+		}
+
+		if _, ok := greek[lower]; ok {
+			latin, consumed := romanizeGreek(runes, i)
+			b.WriteString(matchCase(latin, runes, i))
+			i += consumed - 1
+			continue
+		}
+
+		b.WriteRune(r)
+	}
+
+	return b.String()
+}
+
+// romanizeGreek returns the romanization of the letter (or digraph) at
+// position i and the number of runes it consumed.
+func romanizeGreek(runes []rune, i int) (string, int) {
+	cur := unicode.ToLower(runes[i])
+	next := rune(0)
+	if i+1 < len(runes) {
+		next = unicode.ToLower(runes[i+1])
+	}
+	after := rune(0)
+	if i+2 < len(runes) {
+		after = unicode.ToLower(runes[i+2])
+	}
+	wordStart := i == 0 || !unicode.IsLetter(runes[i-1])
+
+	switch {
+	case cur == 'ο' && (next == 'υ' || next == 'ύ'):
+		return "ou", 2
+	case (cur == 'α' || cur == 'ε' || cur == 'η') && (next == 'υ' || next == 'ύ'):
+		vowel := greek[cur]
+		if after != 0 && strings.ContainsRune(greekVoiced, after) {
+			return vowel + "v", 2
+		}
+		return vowel + "f", 2
+	case cur == 'μ' && next == 'π' && wordStart:
+		return "b", 2
+	case cur == 'ν' && next == 'τ' && wordStart:
+		return "d", 2
+	case cur == 'γ' && (next == 'γ' || next == 'ξ' || next == 'χ'):
+		return "n", 1
+	}
+
+	return greek[cur], 1
+}
+
+// matchCase capitalizes a romanized letter to follow its source: all caps
+// inside an upper-case word, title case at the start of a capitalized word.
+func matchCase(latin string, runes []rune, i int) string {
+	if latin == "" || !unicode.IsUpper(runes[i]) {
+		return latin
+	}
+
+	nextUpper := i+1 < len(runes) && unicode.IsUpper(runes[i+1])
+	prevUpper := i > 0 && unicode.IsUpper(runes[i-1])
+	nextLetter := i+1 < len(runes) && unicode.IsLetter(runes[i+1])
+
+	if nextUpper || (prevUpper && !nextLetter) {
+		return strings.ToUpper(latin)
+	}
+
+	return strings.ToUpper(latin[:1]) + latin[1:]
+}
+
+// IsNonLatin reports whether s contains Cyrillic or Greek letters.
+func IsNonLatin(s string) bool {
+	for _, r := range s {
+		if unicode.In(r, unicode.Cyrillic, unicode.Greek) {
+			return true
+		}
+	}
+	return false
+}
+
+// RomanizeNames returns a copy of the entry with its author, editor and
+// translator names romanized, for citing non-Latin sources in APA style.
+func RomanizeNames(entry *bibtex.Entry) *bibtex.Entry {
+	romanized := &bibtex.Entry{
+		Type:   entry.Type,
+		Key:    entry.Key,
+		Fields: make(map[string]string, len(entry.Fields)),
+	}
+
+	for key, value := range entry.Fields {
+		switch key {
+		case "author", "editor", "translator":
+			if IsNonLatin(value) {
+				value = Romanize(value)
+			}
+		}
+		romanized.Fields[key] = value
+	}
+
+	return romanized
+}
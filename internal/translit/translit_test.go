@@ -0,0 +1,139 @@
+package translit
+
+import (
+	"testing"
+
+	"github.com/knhn1004/bibtext-to-apa6/internal/bibtex"
+)
+
+func TestRomanizeCyrillic(t *testing.T) {
+	tests := []struct {
+		input string
+		want  string
+	}{
+		// Russian
+		{"Достоевский, Фёдор Михайлович", "Dostoevskiy, Fyodor Mikhaylovich"},
+		{"Толстой, Лев", "Tolstoy, Lev"},
+		{"Чехов, Антон Павлович", "Chekhov, Anton Pavlovich"},
+		{"Щедрин", "Shchedrin"},
+		{"Жуковский", "Zhukovskiy"},
+		{"Цветаева", "Tsvetaeva"},
+		{"Юрьев", "Yurev"},
+		{"Яковлев", "Yakovlev"},
+		{"Объедков", "Obedkov"},
+		// Ukrainian and Belarusian
+		{"Шевченко, Тарас", "Shevchenko, Taras"},
+		{"Їжакевич", "Yizhakevich"},
+		{"Євтушенко", "Yevtushenko"},
+		{"Ґалаґан", "Galagan"},
+		{"Ўладзімір", "Wladzimir"},
+		// Serbian
+		{"Ђорђевић", "Djordjevic"},
+		{"Љубица Његош", "Ljubitsa Njegosh"},
+		{"Јован Џаковић", "Jovan Dzakovic"},
+		// Capitals follow the source
+		{"ЖУКОВ", "ZHUKOV"},
+		{"Ж. Иванов", "Zh. Ivanov"},
+		{"Ж.", "Zh."},
+		// Other characters are kept
+		{"Smith, J.", "Smith, J."},
+		{"", ""},
+	}
+
+	for _, tt := range tests {
+		if got := Romanize(tt.input); got != tt.want {
+			t.Errorf("Romanize(%q) = %q, want %q", tt.input, got, tt.want)
+		}
+	}
+}
+
+func TestRomanizeGreek(t *testing.T) {
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{"Παπαδόπουλος, Γιώργος", "Papadopoulos, Giorgos"},
+		{"Καζαντζάκης, Νίκος", "Kazantzakis, Nikos"},
+		{"Θεοδωράκης", "Theodorakis"},
+		{"Χατζηδάκις", "Chatzidakis"},
+		{"Ψυχάρης", "Psycharis"},
+		{"Ξενάκης", "Xenakis"},
+		// Final sigma and accented vowels
+		{"Σεφέρης", "Seferis"},
+		{"Ελύτης", "Elytis"},
+		// αυ and ευ before voiced and voiceless letters
+		{"Ευάγγελος", "Evangelos"},
+		{"Ευθύμιος", "Efthymios"},
+		{"Αυγερινός", "Avgerinos"},
+		{"Ναύπλιο", "Nafplio"},
+		// μπ and ντ at the start of a word, and inside it
+		{"Μπότσαρης", "Botsaris"},
+		{"Ντόρα", "Dora"},
+		{"Λάμπρος", "Lampros"},
+		// γγ, γξ and γχ
+		{"Αγγελόπουλος", "Angelopoulos"},
+		{"Σφίγξ", "Sfinx"},
+		{"Αγχίαλος", "Anchialos"},
+		// Capitals follow the source
+		{"ΣΕΦΕΡΗΣ", "SEFERIS"},
+		{"ΘΕΟΔΩΡΑΚΗΣ", "THEODORAKIS"},
+	}
+
+	for _, tt := range tests {
+		if got := Romanize(tt.input); got != tt.want {
+			t.Errorf("Romanize(%q) = %q, want %q", tt.input, got, tt.want)
+		}
+	}
+}
+
+func TestIsNonLatin(t *testing.T) {
+	tests := []struct {
+		input string
+		want  bool
+	}{
+		{"Достоевский", true},
+		{"Σεφέρης", true},
+		{"Smith, John and Иванов, Иван", true},
+		{"Müller, Jürgen", false},
+		{"{World Health Organization}", false},
+		{"李, 小龙", false},
+		{"", false},
+	}
+
+	for _, tt := range tests {
+		if got := IsNonLatin(tt.input); got != tt.want {
+			t.Errorf("IsNonLatin(%q) = %v, want %v", tt.input, got, tt.want)
+		}
+	}
+}
+
+func TestRomanizeNames(t *testing.T) {
+	entry := &bibtex.Entry{Type: "book", Key: "tolstoy1869", Fields: map[string]string{
+		"author":     "Толстой, Лев",
+		"editor":     "Smith, John",
+		"translator": "Παπαδόπουλος, Γιώργος",
+		"title":      "Война и мир",
+	}}
+
+	got := RomanizeNames(entry)
+
+	want := map[string]string{
+		"author":     "Tolstoy, Lev",
+		"editor":     "Smith, John",
+		"translator": "Papadopoulos, Giorgos",
+		"title":      "Война и мир",
+	}
+	for field, value := range want {
+		if got.Fields[field] != value {
+			t.Errorf("%s = %q, want %q", field, got.Fields[field], value)
+		}
+	}
+	if got.Type != entry.Type || got.Key != entry.Key {
+		t.Errorf("RomanizeNames() = %s %s, want %s %s", got.Type, got.Key, entry.Type, entry.Key)
+	}
+
+	// The original entry is left as it is
+	if entry.Fields["author"] != "Толстой, Лев" {
+		t.Errorf("RomanizeNames() changed the original author to %q", entry.Fields["author"])
+	}
+}
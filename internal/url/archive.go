@@ -0,0 +1,229 @@
+package url
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// DefaultArchiveBaseURL is the Internet Archive's Wayback Machine
+	// availability API.
+	DefaultArchiveBaseURL = "https://archive.org/wayback/available"
+
+	// DefaultSaveBaseURL is the Wayback Machine's Save Page Now endpoint; the
+	// URL to archive is appended to it.
+	DefaultSaveBaseURL = "https://web.archive.org/save/"
+)
+
+// waybackTimestamp is the layout of the timestamps in Wayback Machine URLs.
+const waybackTimestamp = "20060102150405"
+
+// ErrNotArchived is returned when the Wayback Machine has no snapshot of a
+// URL.
+var ErrNotArchived = errors.New("no archived snapshot")
+
+// Snapshot is an archived copy of a page.
+type Snapshot struct {
+	URL        string
+	Timestamp  time.Time
+	StatusCode int // status of the page when it was archived
+}
+
+// Archiver looks up and requests Wayback Machine snapshots. BaseURL and
+// SaveURL can point at a local stand-in for the Internet Archive.
+type Archiver struct {
+	BaseURL string
+	SaveURL string
+	Client  *http.Client
+}
+
+// NewArchiver returns an Archiver that uses the Internet Archive.
+func NewArchiver() *Archiver {
+	return &Archiver{
+		BaseURL: DefaultArchiveBaseURL,
+		SaveURL: DefaultSaveBaseURL,
+		Client:  &http.Client{Timeout: 30 * time.Second},
+	}
+}
+
+// availability is the response of the availability API.
+type availability struct {
+	ArchivedSnapshots struct {
+		Closest *struct {
+			Available bool   `json:"available"`
+			URL       string `json:"url"`
+			Timestamp string `json:"timestamp"`
+			Status    string `json:"status"`
+		} `json:"closest"`
+	} `json:"archived_snapshots"`
+}
+
+// Lookup returns the snapshot of urlStr closest to the present, or
+// ErrNotArchived.
+func (a *Archiver) Lookup(ctx context.Context, urlStr string) (*Snapshot, error) {
+	baseURL := a.BaseURL
+	if baseURL == "" {
+		baseURL = DefaultArchiveBaseURL
+	}
+
+	endpoint, err := url.Parse(baseURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid archive URL: %w", err)
+	}
+	query := endpoint.Query()
+	query.Set("url", urlStr)
+	endpoint.RawQuery = query.Encode()
+
+	req, err := http.NewRequestWithContext(ctx, "GET", endpoint.String(), nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := a.client().Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query archive: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, &HTTPError{StatusCode: resp.StatusCode, Status: resp.Status}
+	}
+
+	var result availability
+	if err := json.NewDecoder(io.LimitReader(resp.Body, maxBodySize)).Decode(&result); err != nil {
+		return nil, fmt.Errorf("failed to parse archive response: %w", err)
+	}
+
+	closest := result.ArchivedSnapshots.Closest
+	if closest == nil || !closest.Available || closest.URL == "" {
+		return nil, ErrNotArchived
+	}
+
+	snapshot := &Snapshot{URL: secureSnapshotURL(closest.URL)}
+	snapshot.Timestamp, _ = time.Parse(waybackTimestamp, closest.Timestamp)
+	snapshot.StatusCode, _ = strconv.Atoi(closest.Status)
+
+	return snapshot, nil
+}
+
+// Save asks the Wayback Machine to archive urlStr now and returns the new
+// snapshot. Saving can take a minute or more.
+func (a *Archiver) Save(ctx context.Context, urlStr string) (*Snapshot, error) {
+	saveURL := a.SaveURL
+	if saveURL == "" {
+		saveURL = DefaultSaveBaseURL
+	}
+	if !strings.HasSuffix(saveURL, "/") {
+		saveURL += "/"
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "GET", saveURL+urlStr, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	resp, err := a.client().Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to request snapshot: %w", err)
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(resp.Body, maxBodySize))
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, &HTTPError{StatusCode: resp.StatusCode, Status: resp.Status}
+	}
+
+	// The snapshot is named by Content-Location, or by the URL we were
+	// redirected to
+	location := resp.Request.URL
+	if header := resp.Header.Get("Content-Location"); header != "" {
+		if ref, err := url.Parse(header); err == nil {
+			location = resp.Request.URL.ResolveReference(ref)
+		}
+	}
+
+	if snapshot := parseSnapshotURL(location); snapshot != nil {
+		return snapshot, nil
+	}
+
+	return a.Lookup(ctx, urlStr)
+}
+
+// Archive returns an existing snapshot of urlStr, requesting one if there
+// is none.
+func (a *Archiver) Archive(ctx context.Context, urlStr string) (*Snapshot, error) {
+	snapshot, err := a.Lookup(ctx, urlStr)
+	if err == nil {
+		return snapshot, nil
+	}
+	if !errors.Is(err, ErrNotArchived) {
+		return nil, err
+	}
+
+	return a.Save(ctx, urlStr)
+}
+
+func (a *Archiver) client() *http.Client {
+	if a.Client != nil {
+		return a.Client
+	}
+	return http.DefaultClient
+}
+
+// parseSnapshotURL reads a /web/<timestamp>/<url> snapshot URL.
+func parseSnapshotURL(u *url.URL) *Snapshot {
+	parts := strings.SplitN(strings.TrimPrefix(u.Path, "/"), "/", 3)
+	if len(parts) < 3 || parts[0] != "web" {
+		return nil
+	}
+
+	timestamp, err := time.Parse(waybackTimestamp, parts[1])
+	if err != nil {
+		return nil
+	}
+
+	return &Snapshot{URL: secureSnapshotURL(u.String()), Timestamp: timestamp, StatusCode: http.StatusOK}
+}
+
+// secureSnapshotURL upgrades the http:// URLs the availability API returns.
+func secureSnapshotURL(urlStr string) string {
+	if strings.HasPrefix(urlStr, "http://web.archive.org/") {
+		return "https://" + strings.TrimPrefix(urlStr, "http://")
+	}
+	return urlStr
+}
+
+// IsArchiveURL reports whether urlStr is a Wayback Machine snapshot.
+func IsArchiveURL(urlStr string) bool {
+	u, err := url.Parse(urlStr)
+	if err != nil {
+		return false
+	}
+	return u.Hostname() == "web.archive.org" && parseSnapshotURL(u) != nil
+}
+
+// DeadLink reports whether a URL that used to work has died: its host no
+// longer resolves, or the server answers 404 Not Found or 410 Gone. Other
+// failures, such as timeouts and server errors, may be temporary and are
+// returned as errors.
+func DeadLink(ctx context.Context, client *http.Client, urlStr string) (bool, error) {
+	checker := &LinkChecker{Client: client}
+	result := checker.Check(ctx, urlStr)
+
+	switch result.Status {
+	case LinkDead:
+		return true, nil
+	case LinkError:
+		return false, errors.New(result.Error)
+	}
+	return false, nil
+}
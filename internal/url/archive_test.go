@@ -0,0 +1,216 @@
+package url
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// newArchiveServer stands in for the Wayback Machine. The availability API
+// at /available replays the responses recorded in testdata/archive, and Save
+// Page Now at /save/ names the new snapshot the way the page asks.
+func newArchiveServer(t *testing.T) (*httptest.Server, *[]string) {
+	t.Helper()
+
+	saved := []string{}
+	var server *httptest.Server
+	// A ServeMux would clean the "//" out of the archived URLs
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.URL.Path == "/available":
+			switch r.URL.Query().Get("url") {
+			case "https://example.com/articles/rivers":
+				serveFixture(t, w, "archive/available.json", "application/json")
+			case "https://example.com/down":
+				http.Error(w, "unavailable", http.StatusServiceUnavailable)
+			default:
+				serveFixture(t, w, "archive/unavailable.json", "application/json")
+			}
+
+		case strings.HasPrefix(r.URL.Path, "/save/"):
+			target := strings.TrimPrefix(r.URL.Path, "/save/")
+			saved = append(saved, target)
+			switch target {
+			case "https://example.com/new":
+				w.Header().Set("Content-Location", "/web/20240501120000/"+target)
+			case "https://example.com/redirected":
+				http.Redirect(w, r, server.URL+"/web/20240502080000/"+target, http.StatusFound)
+				return
+			case "https://example.com/blocked":
+				http.Error(w, "forbidden", http.StatusForbidden)
+				return
+			}
+			w.Write([]byte("<html></html>"))
+
+		case strings.HasPrefix(r.URL.Path, "/web/"):
+			w.Write([]byte("<html></html>"))
+
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(server.Close)
+
+	return server, &saved
+}
+
+func newTestArchiver(server *httptest.Server) *Archiver {
+	return &Archiver{
+		BaseURL: server.URL + "/available",
+		SaveURL: server.URL + "/save",
+		Client:  server.Client(),
+	}
+}
+
+func TestArchiverLookup(t *testing.T) {
+	server, _ := newArchiveServer(t)
+	archiver := newTestArchiver(server)
+
+	snapshot, err := archiver.Lookup(context.Background(), "https://example.com/articles/rivers")
+	if err != nil {
+		t.Fatalf("Lookup() error = %v", err)
+	}
+	if want := "https://web.archive.org/web/20230105100000/https://example.com/articles/rivers"; snapshot.URL != want {
+		t.Errorf("URL = %q, want %q", snapshot.URL, want)
+	}
+	if want := time.Date(2023, 1, 5, 10, 0, 0, 0, time.UTC); !snapshot.Timestamp.Equal(want) {
+		t.Errorf("Timestamp = %v, want %v", snapshot.Timestamp, want)
+	}
+	if snapshot.StatusCode != http.StatusOK {
+		t.Errorf("StatusCode = %d, want 200", snapshot.StatusCode)
+	}
+
+	if _, err := archiver.Lookup(context.Background(), "https://example.com/new"); !errors.Is(err, ErrNotArchived) {
+		t.Errorf("Lookup() of an unarchived URL error = %v, want ErrNotArchived", err)
+	}
+
+	_, err = archiver.Lookup(context.Background(), "https://example.com/down")
+	var httpErr *HTTPError
+	if !errors.As(err, &httpErr) || httpErr.StatusCode != http.StatusServiceUnavailable {
+		t.Errorf("Lookup() with the API down error = %v, want an HTTPError with status 503", err)
+	}
+}
+
+func TestArchiverSave(t *testing.T) {
+	server, _ := newArchiveServer(t)
+	archiver := newTestArchiver(server)
+
+	tests := []struct {
+		urlStr string
+		want   string
+		when   time.Time
+	}{
+		{
+			urlStr: "https://example.com/new",
+			want:   server.URL + "/web/20240501120000/https://example.com/new",
+			when:   time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC),
+		},
+		{
+			urlStr: "https://example.com/redirected",
+			want:   server.URL + "/web/20240502080000/https://example.com/redirected",
+			when:   time.Date(2024, 5, 2, 8, 0, 0, 0, time.UTC),
+		},
+		{
+			// Neither names the snapshot, so it is looked up instead
+			urlStr: "https://example.com/articles/rivers",
+			want:   "https://web.archive.org/web/20230105100000/https://example.com/articles/rivers",
+			when:   time.Date(2023, 1, 5, 10, 0, 0, 0, time.UTC),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.urlStr, func(t *testing.T) {
+			snapshot, err := archiver.Save(context.Background(), tt.urlStr)
+			if err != nil {
+				t.Fatalf("Save() error = %v", err)
+			}
+			if snapshot.URL != tt.want {
+				t.Errorf("URL = %q, want %q", snapshot.URL, tt.want)
+			}
+			if !snapshot.Timestamp.Equal(tt.when) {
+				t.Errorf("Timestamp = %v, want %v", snapshot.Timestamp, tt.when)
+			}
+		})
+	}
+
+	_, err := archiver.Save(context.Background(), "https://example.com/blocked")
+	var httpErr *HTTPError
+	if !errors.As(err, &httpErr) || httpErr.StatusCode != http.StatusForbidden {
+		t.Errorf("Save() of a refused URL error = %v, want an HTTPError with status 403", err)
+	}
+}
+
+func TestArchiverArchive(t *testing.T) {
+	server, saved := newArchiveServer(t)
+	archiver := newTestArchiver(server)
+
+	// An existing snapshot is used without saving a new one
+	snapshot, err := archiver.Archive(context.Background(), "https://example.com/articles/rivers")
+	if err != nil {
+		t.Fatalf("Archive() error = %v", err)
+	}
+	if !strings.HasPrefix(snapshot.URL, "https://web.archive.org/web/20230105100000/") {
+		t.Errorf("URL = %q, want the existing snapshot", snapshot.URL)
+	}
+	if len(*saved) != 0 {
+		t.Errorf("saved %v, want no new snapshot", *saved)
+	}
+
+	snapshot, err = archiver.Archive(context.Background(), "https://example.com/new")
+	if err != nil {
+		t.Fatalf("Archive() error = %v", err)
+	}
+	if want := server.URL + "/web/20240501120000/https://example.com/new"; snapshot.URL != want {
+		t.Errorf("URL = %q, want %q", snapshot.URL, want)
+	}
+	if len(*saved) != 1 || (*saved)[0] != "https://example.com/new" {
+		t.Errorf("saved %v, want https://example.com/new", *saved)
+	}
+
+	// Failures other than a missing snapshot are not papered over by saving
+	if _, err := archiver.Archive(context.Background(), "https://example.com/down"); err == nil {
+		t.Errorf("Archive() with the API down succeeded")
+	}
+	if len(*saved) != 1 {
+		t.Errorf("saved %v after a failed lookup, want no new snapshot", *saved)
+	}
+}
+
+func TestDeadLink(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/gone":
+			w.WriteHeader(http.StatusGone)
+		case "/missing":
+			http.NotFound(w, r)
+		case "/broken":
+			w.WriteHeader(http.StatusInternalServerError)
+		default:
+			w.Write([]byte("<html></html>"))
+		}
+	}))
+	defer server.Close()
+
+	tests := []struct {
+		path    string
+		dead    bool
+		wantErr bool
+	}{
+		{"/live", false, false},
+		{"/missing", true, false},
+		{"/gone", true, false},
+		// Server errors may be temporary
+		{"/broken", false, true},
+	}
+
+	for _, tt := range tests {
+		dead, err := DeadLink(context.Background(), server.Client(), server.URL+tt.path)
+		if dead != tt.dead || (err != nil) != tt.wantErr {
+			t.Errorf("DeadLink(%s) = %v, %v, want %v, error %v", tt.path, dead, err, tt.dead, tt.wantErr)
+		}
+	}
+}
@@ -0,0 +1,113 @@
+package url
+
+import (
+	"context"
+	"encoding/xml"
+	"fmt"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/knhn1004/bibtext-to-apa6/internal/bibtex"
+	"github.com/knhn1004/bibtext-to-apa6/internal/doi"
+)
+
+// DefaultArXivBaseURL is the arXiv Atom query API.
+const DefaultArXivBaseURL = "https://export.arxiv.org/api/query"
+
+var arxivVersion = regexp.MustCompile(`v\d+$`)
+
+// ArXivResolver looks up preprints through the arXiv Atom API.
+type ArXivResolver struct {
+	BaseURL string
+	Client  *http.Client
+}
+
+// NewArXivResolver returns a resolver that queries export.arxiv.org.
+func NewArXivResolver() *ArXivResolver {
+	return &ArXivResolver{
+		BaseURL: DefaultArXivBaseURL,
+		Client:  &http.Client{Timeout: 15 * time.Second},
+	}
+}
+
+type arxivFeed struct {
+	Entries []arxivEntry `xml:"http://www.w3.org/2005/Atom entry"`
+}
+
+type arxivEntry struct {
+	ID        string `xml:"http://www.w3.org/2005/Atom id"`
+	Title     string `xml:"http://www.w3.org/2005/Atom title"`
+	Published string `xml:"http://www.w3.org/2005/Atom published"`
+	Authors   []struct {
+		Name string `xml:"http://www.w3.org/2005/Atom name"`
+	} `xml:"http://www.w3.org/2005/Atom author"`
+	DOI        string `xml:"http://arxiv.org/schemas/atom doi"`
+	JournalRef string `xml:"http://arxiv.org/schemas/atom journal_ref"`
+}
+
+// Resolve fetches the metadata for an arXiv ID such as "2301.01234" or
+// "hep-th/9901001".
+func (r *ArXivResolver) Resolve(ctx context.Context, id string) (*bibtex.Entry, error) {
+	baseURL := r.BaseURL
+	if baseURL == "" {
+		baseURL = DefaultArXivBaseURL
+	}
+
+	query := url.Values{"id_list": {id}}
+	body, err := getBody(ctx, r.Client, baseURL+"?"+query.Encode(), "application/atom+xml")
+	if err != nil {
+		return nil, err
+	}
+
+	var feed arxivFeed
+	if err := xml.Unmarshal(body, &feed); err != nil {
+		return nil, fmt.Errorf("failed to parse arXiv response: %w", err)
+	}
+
+	// Unknown IDs come back as an entry titled "Error", with an id under
+	// arxiv.org/api/errors and "arXiv api core" as its author
+	if len(feed.Entries) == 0 || len(feed.Entries[0].Authors) == 0 || strings.Contains(feed.Entries[0].ID, "/api/errors") {
+		return nil, fmt.Errorf("arXiv ID not found: %s", id)
+	}
+
+	return feed.Entries[0].toEntry(id), nil
+}
+
+func (a *arxivEntry) toEntry(id string) *bibtex.Entry {
+	entry := &bibtex.Entry{
+		Type:   "misc",
+		Fields: make(map[string]string),
+	}
+
+	authors := []string{}
+	for _, author := range a.Authors {
+		if name := strings.TrimSpace(author.Name); name != "" {
+			authors = append(authors, name)
+		}
+	}
+
+	entry.Fields["author"] = strings.Join(authors, " and ")
+	entry.Fields["title"] = strings.Join(strings.Fields(a.Title), " ")
+	if year := extractYearFromDate(a.Published); year != "" {
+		entry.Fields["year"] = year
+	}
+
+	bareID := arxivVersion.ReplaceAllString(id, "")
+	entry.Fields["eprint"] = bareID
+	entry.Fields["archiveprefix"] = "arXiv"
+	entry.Fields["url"] = "https://arxiv.org/abs/" + bareID
+
+	if d := doi.Normalize(a.DOI); d != "" {
+		entry.Fields["doi"] = d
+	}
+	if ref := strings.TrimSpace(a.JournalRef); ref != "" {
+		entry.Fields["note"] = ref
+	}
+
+	entry.Key = entry.GenerateKey("arxiv")
+
+	return entry
+}
@@ -0,0 +1,196 @@
+package url
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/knhn1004/bibtext-to-apa6/internal/bibtex"
+)
+
+var (
+	// bylinePrefix matches the lead-in of a byline, as in "By Jane Doe" or
+	// "Written by: Jane Doe".
+	bylinePrefix = regexp.MustCompile(`(?i)^(?:(?:written|posted|reported|words|story)\s+)?by:?\s+`)
+
+	// bylineSeparator splits a byline listing several authors.
+	bylineSeparator = regexp.MustCompile(`(?i)\s*(?:,|;|\||&|\band\b)\s*`)
+)
+
+// nameSuffixes follow a family name and are not names in their own right.
+// They are looked up without periods. Generational suffixes are kept;
+// degrees, which APA omits, are dropped.
+var nameSuffixes = map[string]bool{
+	"jr": true, "sr": true, "ii": true, "iii": true, "iv": true,
+
+	"phd": false, "dphil": false, "md": false, "dds": false, "jd": false,
+	"edd": false, "psyd": false, "mph": false, "mba": false, "msc": false,
+	"bsc": false, "rn": false,
+}
+
+// nameSuffix reports whether word is a name suffix, and whether it is kept.
+func nameSuffix(word string) (keep, ok bool) {
+	keep, ok = nameSuffixes[strings.ToLower(strings.ReplaceAll(word, ".", ""))]
+	return keep, ok
+}
+
+// organizationWords mark a name as an organization rather than a person.
+var organizationWords = map[string]bool{
+	"agency": true, "associated": true, "association": true, "board": true,
+	"bureau": true, "center": true, "centre": true, "college": true,
+	"commission": true, "committee": true, "company": true, "corp": true,
+	"corporation": true, "council": true, "department": true, "desk": true,
+	"editors": true, "editorial": true, "foundation": true, "government": true,
+	"group": true, "inc": true, "institute": true, "institutes": true,
+	"laboratory": true, "llc": true, "ltd": true,
+	"media": true, "ministry": true, "news": true, "office": true,
+	"organisation": true, "organization": true, "press": true, "service": true,
+	"society": true, "staff": true, "team": true, "university": true,
+}
+
+// ParseByline splits a byline such as "By Jane Doe, John Smith and Ann Lee"
+// into names. Inverted names such as "Doe, Jane" or "Doe, Jane, Smith,
+// John" are read in pairs.
+func ParseByline(byline string) []bibtex.Name {
+	byline = bylinePrefix.ReplaceAllString(strings.Join(strings.Fields(byline), " "), "")
+	if byline == "" || isURLOrEmail(byline) {
+		return nil
+	}
+
+	if names := invertedNames(byline); names != nil {
+		return names
+	}
+
+	parts := bylineSeparator.Split(byline, -1)
+
+	names := []bibtex.Name{}
+	for _, part := range parts {
+		part = strings.TrimSpace(part)
+		if part == "" || isURLOrEmail(part) {
+			continue
+		}
+
+		// "Martin Luther King, Jr." is one name, and "Jane Doe, PhD" is
+		// cited without the degree
+		if keep, ok := nameSuffix(part); ok && len(names) > 0 {
+			if last := &names[len(names)-1]; keep && !last.Corporate {
+				last.Suffix = part
+			}
+			continue
+		}
+
+		// A one-word name alone, such as "Reuters", is an organization;
+		// in a list of names it is more likely a family name
+		if len(parts) > 1 && len(strings.Fields(part)) == 1 && !isOrganization(part) {
+			names = append(names, bibtex.Name{Family: part})
+			continue
+		}
+
+		names = append(names, parseAuthorName(part))
+	}
+
+	return names
+}
+
+// isInvertedName reports whether a byline is one "Family, Given" name
+// rather than a comma-separated list of names.
+func isInvertedName(byline string) bool {
+	parts := strings.Split(byline, ",")
+	return len(parts) == 2 && !strings.ContainsAny(byline, "&;|") && isInvertedPair(parts[0], parts[1])
+}
+
+// invertedNames reads a byline of one or more "Family, Given" names
+// separated only by commas, as in "Doe, Jane, Smith, John". It returns nil
+// for other bylines.
+func invertedNames(byline string) []bibtex.Name {
+	if strings.ContainsAny(byline, "&;|") || len(bylineSeparator.FindAllString(byline, -1)) != strings.Count(byline, ",") {
+		return nil
+	}
+
+	parts := strings.Split(byline, ",")
+	if len(parts)%2 != 0 {
+		return nil
+	}
+
+	names := []bibtex.Name{}
+	for i := 0; i < len(parts); i += 2 {
+		if !isInvertedPair(parts[i], parts[i+1]) {
+			return nil
+		}
+		names = append(names, bibtex.Name{Family: strings.TrimSpace(parts[i]), Given: strings.TrimSpace(parts[i+1])})
+	}
+
+	return names
+}
+
+// isInvertedPair reports whether family and given are the two halves of an
+// inverted name: a one-word family name, and a first name with optional
+// initials.
+func isInvertedPair(family, given string) bool {
+	familyWords := strings.Fields(family)
+	givenWords := strings.Fields(given)
+	if len(familyWords) != 1 || len(givenWords) == 0 {
+		return false
+	}
+	if _, ok := nameSuffix(givenWords[0]); ok {
+		return false
+	}
+	if isOrganization(family) || isOrganization(given) {
+		return false
+	}
+
+	for _, word := range givenWords[1:] {
+		if len(strings.TrimSuffix(word, ".")) > 1 {
+			return false
+		}
+	}
+
+	return true
+}
+
+// parseAuthorName parses one author as named in page metadata, telling
+// people apart from organizations. A name of one word, such as "Reuters", is
+// taken to be an organization.
+func parseAuthorName(name string) bibtex.Name {
+	name = strings.TrimSpace(bylinePrefix.ReplaceAllString(strings.Join(strings.Fields(name), " "), ""))
+	if isOrganization(name) || len(strings.Fields(name)) == 1 {
+		return bibtex.Name{Family: name, Corporate: true}
+	}
+	return bibtex.ParseName(name)
+}
+
+// isOrganization guesses whether a name belongs to an organization: an
+// acronym ("NASA"), a long name, one starting with "The", or one containing
+// "of", "for" or words such as "Institute" or "Staff".
+func isOrganization(name string) bool {
+	if bibtex.IsCorporateName(name) {
+		return true
+	}
+
+	words := strings.Fields(name)
+	switch {
+	case len(words) == 0:
+		return false
+	case len(words) == 1:
+		return acronym.MatchString(words[0])
+	case len(words) > 5:
+		return true
+	case words[0] == "The":
+		return true
+	}
+
+	for _, word := range words {
+		word = strings.ToLower(strings.Trim(word, ".,()"))
+		if organizationWords[word] || word == "of" || word == "for" {
+			return true
+		}
+	}
+
+	return false
+}
+
+// acronym matches an all-capitals name such as "NASA" or "BBC".
+var acronym = regexp.MustCompile(`^\p{Lu}{2,}$`)
+
+func isURLOrEmail(s string) bool {
+	return strings.HasPrefix(s, "http://") || strings.HasPrefix(s, "https://") || strings.Contains(s, "@")
+}
@@ -0,0 +1,77 @@
+package url
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/knhn1004/bibtext-to-apa6/internal/bibtex"
+)
+
+func TestParseByline(t *testing.T) {
+	tests := []struct {
+		byline string
+		want   []bibtex.Name
+	}{
+		{"", nil},
+		{"https://example.com/staff/jane", nil},
+		{"By Jane Doe", []bibtex.Name{{Family: "Doe", Given: "Jane"}}},
+		{"Written by: Jane Doe", []bibtex.Name{{Family: "Doe", Given: "Jane"}}},
+		{"Doe, Jane", []bibtex.Name{{Family: "Doe", Given: "Jane"}}},
+		{"Doe, Jane A.", []bibtex.Name{{Family: "Doe", Given: "Jane A."}}},
+		{
+			"Doe, Jane, Smith, John",
+			[]bibtex.Name{{Family: "Doe", Given: "Jane"}, {Family: "Smith", Given: "John"}},
+		},
+		{
+			"By Jane Doe, John Smith and Ann Lee",
+			[]bibtex.Name{{Family: "Doe", Given: "Jane"}, {Family: "Smith", Given: "John"}, {Family: "Lee", Given: "Ann"}},
+		},
+		{
+			"Jane Doe & John Smith",
+			[]bibtex.Name{{Family: "Doe", Given: "Jane"}, {Family: "Smith", Given: "John"}},
+		},
+		{"Jane Doe, PhD", []bibtex.Name{{Family: "Doe", Given: "Jane"}}},
+		{"Jane Doe, Ph.D., and John Smith, M.D.", []bibtex.Name{{Family: "Doe", Given: "Jane"}, {Family: "Smith", Given: "John"}}},
+		{"Martin Luther King, Jr.", []bibtex.Name{{Family: "King", Given: "Martin Luther", Suffix: "Jr."}}},
+		{
+			"Jane Doe | The Times",
+			[]bibtex.Name{{Family: "Doe", Given: "Jane"}, {Family: "The Times", Corporate: true}},
+		},
+		{"Reuters", []bibtex.Name{{Family: "Reuters", Corporate: true}}},
+		{"Jane Doe and Reuters", []bibtex.Name{{Family: "Doe", Given: "Jane"}, {Family: "Reuters"}}},
+		{"Jane Doe and AP", []bibtex.Name{{Family: "Doe", Given: "Jane"}, {Family: "AP", Corporate: true}}},
+		{"National Institutes of Health", []bibtex.Name{{Family: "National Institutes of Health", Corporate: true}}},
+		{"Associated Press Staff", []bibtex.Name{{Family: "Associated Press Staff", Corporate: true}}},
+	}
+
+	for _, tt := range tests {
+		got := ParseByline(tt.byline)
+		if (len(got) > 0 || len(tt.want) > 0) && !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("ParseByline(%q) = %#v, want %#v", tt.byline, got, tt.want)
+		}
+	}
+}
+
+func TestIsInvertedName(t *testing.T) {
+	tests := []struct {
+		byline string
+		want   bool
+	}{
+		{"Doe, Jane", true},
+		{"Doe, Jane A.", true},
+		{"Doe, J. R.", true},
+		{"Jane Doe, John Smith", false},
+		{"Doe, Jane Ann", false},
+		{"Doe, Jr.", false},
+		{"Doe, PhD", false},
+		{"Doe, Jane; Smith", false},
+		{"Doe, Jane, Smith, John", false},
+		{"Reuters, NASA", false},
+	}
+
+	for _, tt := range tests {
+		if got := isInvertedName(tt.byline); got != tt.want {
+			t.Errorf("isInvertedName(%q) = %v, want %v", tt.byline, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,240 @@
+package url
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+// BatchResult is the outcome of extracting one URL in a batch.
+type BatchResult struct {
+	URL      string
+	Metadata *Metadata
+	Err      error
+	Attempts int
+}
+
+// BatchExtractor extracts metadata for many URLs concurrently, with a bounded
+// number of workers, a politeness delay between requests to the same host,
+// and retries with exponential backoff.
+type BatchExtractor struct {
+	Extractor *Extractor
+	Workers   int
+	HostDelay time.Duration
+	Retries   int
+	Backoff   time.Duration
+
+	// Progress, if set, is called after each URL completes.
+	Progress func(done, total int, result BatchResult)
+}
+
+// NewBatchExtractor returns a BatchExtractor with 4 workers, one request per
+// host per second and two retries.
+func NewBatchExtractor(extractor *Extractor) *BatchExtractor {
+	return &BatchExtractor{
+		Extractor: extractor,
+		Workers:   4,
+		HostDelay: time.Second,
+		Retries:   2,
+		Backoff:   2 * time.Second,
+	}
+}
+
+// Run extracts all URLs and returns their results in input order.
+func (b *BatchExtractor) Run(ctx context.Context, urls []string) []BatchResult {
+	results := make([]BatchResult, len(urls))
+	limiter := newHostLimiter(b.HostDelay)
+
+	workers := b.Workers
+	if workers < 1 {
+		workers = 1
+	}
+
+	jobs := make(chan int)
+	var (
+		wg       sync.WaitGroup
+		mu       sync.Mutex
+		finished int
+	)
+
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				results[i] = b.extract(ctx, limiter, urls[i])
+
+				if b.Progress != nil {
+					mu.Lock()
+					finished++
+					b.Progress(finished, len(urls), results[i])
+					mu.Unlock()
+				}
+			}
+		}()
+	}
+
+	for i := range urls {
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+
+	return results
+}
+
+func (b *BatchExtractor) extract(ctx context.Context, limiter *hostLimiter, urlStr string) BatchResult {
+	result := BatchResult{URL: urlStr}
+
+	u, err := url.Parse(urlStr)
+	if err != nil || u.Hostname() == "" {
+		result.Err = fmt.Errorf("invalid URL: %s", urlStr)
+		return result
+	}
+
+	backoff := b.Backoff
+	for attempt := 0; attempt <= b.Retries; attempt++ {
+		if attempt > 0 {
+			select {
+			case <-time.After(backoff):
+			case <-ctx.Done():
+				result.Err = ctx.Err()
+				return result
+			}
+			backoff *= 2
+		}
+
+		if err := limiter.Wait(ctx, u.Hostname()); err != nil {
+			result.Err = err
+			return result
+		}
+
+		result.Attempts++
+		result.Metadata, result.Err = b.Extractor.Extract(ctx, urlStr)
+		if result.Err == nil || !retryable(result.Err) {
+			return result
+		}
+	}
+
+	return result
+}
+
+// retryable reports whether an error is worth retrying: network failures
+// such as timeouts and refused connections, rate limiting and server errors.
+// Anything else, such as a 404, a page disallowed by robots.txt, a missing
+// browser or an invalid URL, would fail the same way again.
+func retryable(err error) bool {
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+
+	var httpErr *HTTPError
+	if errors.As(err, &httpErr) {
+		return httpErr.StatusCode == http.StatusTooManyRequests || httpErr.StatusCode >= 500
+	}
+
+	// A host that does not exist will not exist a moment later either
+	var dnsErr *net.DNSError
+	if errors.As(err, &dnsErr) && dnsErr.IsNotFound {
+		return false
+	}
+
+	var netErr net.Error
+	return errors.As(err, &netErr)
+}
+
+// hostLimiter spaces out requests to the same host.
+type hostLimiter struct {
+	mu    sync.Mutex
+	delay time.Duration
+	next  map[string]time.Time
+}
+
+func newHostLimiter(delay time.Duration) *hostLimiter {
+	return &hostLimiter{delay: delay, next: make(map[string]time.Time)}
+}
+
+// Wait blocks until a request to host is allowed.
+func (l *hostLimiter) Wait(ctx context.Context, host string) error {
+	l.mu.Lock()
+	now := time.Now()
+	start := l.next[host]
+	if start.Before(now) {
+		start = now
+	}
+	l.next[host] = start.Add(l.delay)
+	l.mu.Unlock()
+
+	wait := time.Until(start)
+	if wait <= 0 {
+		return nil
+	}
+
+	select {
+	case <-time.After(wait):
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// ReadURLList reads one URL per line, skipping blank lines, "#" comments and
+// duplicates.
+func ReadURLList(r io.Reader) ([]string, error) {
+	urls := []string{}
+	seen := map[string]bool{}
+
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		key := NormalizeURL(line)
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		urls = append(urls, line)
+	}
+
+	return urls, scanner.Err()
+}
+
+// BatchSummary splits batch results into successes and failures.
+type BatchSummary struct {
+	Succeeded []BatchResult
+	Failed    []BatchResult
+}
+
+// Summarize groups batch results by outcome.
+func Summarize(results []BatchResult) BatchSummary {
+	var summary BatchSummary
+	for _, r := range results {
+		if r.Err != nil {
+			summary.Failed = append(summary.Failed, r)
+		} else {
+			summary.Succeeded = append(summary.Succeeded, r)
+		}
+	}
+	return summary
+}
+
+// String renders the summary as a short report listing each failure.
+func (s BatchSummary) String() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%d succeeded, %d failed\n", len(s.Succeeded), len(s.Failed))
+	for _, r := range s.Failed {
+		fmt.Fprintf(&b, "  %s: %v\n", r.URL, r.Err)
+	}
+	return b.String()
+}
@@ -0,0 +1,129 @@
+package url
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync/atomic"
+	"syscall"
+	"testing"
+	"time"
+)
+
+func TestBatchExtractor(t *testing.T) {
+	var flaky, missing int32
+	server := newFixtureServer(t)
+	mux := http.NewServeMux()
+	mux.HandleFunc("/flaky", func(w http.ResponseWriter, r *http.Request) {
+		// Fails once, then serves the article
+		if atomic.AddInt32(&flaky, 1) == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		http.Redirect(w, r, server.URL+"/article", http.StatusFound)
+	})
+	mux.HandleFunc("/missing", func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&missing, 1)
+		http.NotFound(w, r)
+	})
+	other := httptest.NewServer(mux)
+	defer other.Close()
+
+	// The browser stand-in fails for every page, as it would without
+	// Playwright installed
+	extractor := NewExtractor(NewHTTPFetcher(), &failingFetcher{err: errors.New("browser unavailable")})
+	batch := NewBatchExtractor(extractor)
+	batch.HostDelay = 0
+	batch.Backoff = time.Millisecond
+
+	results := batch.Run(context.Background(), []string{
+		server.URL + "/article",
+		other.URL + "/flaky",
+		other.URL + "/missing",
+		"not a url",
+	})
+
+	if results[0].Err != nil || results[0].Metadata.Title != "Rivers of the Pacific Northwest" {
+		t.Errorf("article: %+v", results[0])
+	}
+	if results[1].Err != nil || results[1].Attempts != 2 {
+		t.Errorf("flaky: Err = %v, Attempts = %d, want success on the second attempt", results[1].Err, results[1].Attempts)
+	}
+
+	var httpErr *HTTPError
+	if !errors.As(results[2].Err, &httpErr) || httpErr.StatusCode != http.StatusNotFound {
+		t.Errorf("missing: Err = %v, want an HTTPError with status 404", results[2].Err)
+	}
+	if results[2].Attempts != 1 || atomic.LoadInt32(&missing) != 1 {
+		t.Errorf("missing: %d attempts and %d requests, want a 404 not to be retried", results[2].Attempts, missing)
+	}
+
+	if results[3].Err == nil || results[3].Attempts != 0 {
+		t.Errorf("invalid URL: %+v", results[3])
+	}
+}
+
+func TestRetryable(t *testing.T) {
+	refused := &url.Error{Op: "Get", URL: "https://example.com", Err: &net.OpError{Op: "dial", Net: "tcp", Err: syscall.ECONNREFUSED}}
+	timeout := &url.Error{Op: "Get", URL: "https://example.com", Err: &net.DNSError{Err: "i/o timeout", Name: "example.com", IsTimeout: true}}
+	notFound := &url.Error{Op: "Get", URL: "https://example.com", Err: &net.DNSError{Err: "no such host", Name: "example.com", IsNotFound: true}}
+
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{"connection refused", fmt.Errorf("failed to fetch URL: %w", refused), true},
+		{"timeout", fmt.Errorf("failed to fetch URL: %w", timeout), true},
+		{"rate limited", &HTTPError{StatusCode: http.StatusTooManyRequests}, true},
+		{"server error", &HTTPError{StatusCode: http.StatusServiceUnavailable}, true},
+		{"server error and no browser", errors.Join(&HTTPError{StatusCode: http.StatusBadGateway}, ErrPlaywrightNotInstalled), true},
+
+		{"not found", &HTTPError{StatusCode: http.StatusNotFound}, false},
+		{"forbidden", &HTTPError{StatusCode: http.StatusForbidden}, false},
+		{"no such host", fmt.Errorf("failed to fetch URL: %w", notFound), false},
+		{"disallowed", fmt.Errorf("%w: https://example.com/private", ErrDisallowed), false},
+		{"browser closed", ErrBrowserClosed, false},
+		{"no browser", fmt.Errorf("failed to start playwright: %w", ErrPlaywrightNotInstalled), false},
+		{"invalid URL", fmt.Errorf("invalid URL: %s", "not a url"), false},
+		{"parse failure", errors.New("failed to parse HTML"), false},
+		{"canceled", &url.Error{Op: "Get", URL: "https://example.com", Err: context.Canceled}, false},
+		{"deadline", context.DeadlineExceeded, false},
+	}
+
+	for _, tt := range tests {
+		if got := retryable(tt.err); got != tt.want {
+			t.Errorf("%s: retryable(%v) = %v, want %v", tt.name, tt.err, got, tt.want)
+		}
+	}
+}
+
+func TestBatchExtractorRetries(t *testing.T) {
+	// A closed server refuses connections
+	server := httptest.NewServer(http.NotFoundHandler())
+	closedURL := server.URL + "/article"
+	server.Close()
+
+	noBrowser := &failingFetcher{err: fmt.Errorf("failed to launch browser: %w", ErrPlaywrightNotInstalled)}
+	batch := NewBatchExtractor(NewExtractor(noBrowser))
+	batch.HostDelay = 0
+	batch.Backoff = time.Millisecond
+
+	results := batch.Run(context.Background(), []string{"https://example.com/article"})
+	if results[0].Attempts != 1 || noBrowser.calls != 1 {
+		t.Errorf("no browser: %d attempts, want a missing browser not to be retried", results[0].Attempts)
+	}
+
+	batch = NewBatchExtractor(NewExtractor(NewHTTPFetcher()))
+	batch.HostDelay = 0
+	batch.Backoff = time.Millisecond
+
+	results = batch.Run(context.Background(), []string{closedURL})
+	if results[0].Err == nil || results[0].Attempts != batch.Retries+1 {
+		t.Errorf("refused: Err = %v, Attempts = %d, want %d attempts", results[0].Err, results[0].Attempts, batch.Retries+1)
+	}
+}
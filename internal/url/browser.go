@@ -0,0 +1,431 @@
+package url
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/playwright-community/playwright-go"
+)
+
+// ErrPlaywrightNotInstalled is returned when the Playwright driver or its
+// Chromium build cannot be found.
+var ErrPlaywrightNotInstalled = errors.New("playwright is not installed; run make playwright-install")
+
+// ErrBrowserClosed is returned by a BrowserPool after Close.
+var ErrBrowserClosed = errors.New("browser pool is closed")
+
+// BrowserPool renders pages in headless Chromium through Playwright, for
+// sites that block plain HTTP clients or build their metadata in JavaScript.
+// Chromium is started on first use and shared by later fetches; browser
+// contexts are reused, and at most MaxPages pages are open at once.
+type BrowserPool struct {
+	UserAgent string
+	Timeout   time.Duration
+	MaxPages  int
+
+	// Proxy is the proxy URL Chromium is launched with, if any. Header and
+	// Cookies are sent with every page, and Robots, if set, refuses pages
+	// disallowed by robots.txt.
+	Proxy   string
+	Header  http.Header
+	Cookies []*http.Cookie
+	Robots  *Robots
+
+	mu      sync.Mutex
+	pw      *playwright.Playwright
+	browser playwright.Browser
+	idle    []playwright.BrowserContext
+	slots   chan struct{}
+	done    chan struct{} // closed by Close, waking fetches waiting for a slot
+	closed  bool
+}
+
+// NewBrowserPool returns a BrowserPool with a 30 second navigation timeout
+// and up to 4 open pages.
+func NewBrowserPool() *BrowserPool {
+	return &BrowserPool{
+		UserAgent: defaultUserAgent,
+		Timeout:   30 * time.Second,
+		MaxPages:  4,
+	}
+}
+
+func (p *BrowserPool) Fetch(ctx context.Context, urlStr string) (*Page, error) {
+	if p.Robots != nil {
+		if err := p.Robots.Check(ctx, urlStr); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := p.acquire(ctx); err != nil {
+		return nil, err
+	}
+	defer p.release()
+
+	browserContext, err := p.context()
+	if err != nil {
+		return nil, err
+	}
+
+	// put clears cookies, so they are added for each fetch
+	if err := p.addCookies(browserContext, urlStr); err != nil {
+		browserContext.Close()
+		return nil, err
+	}
+
+	page, err := browserContext.NewPage()
+	if err != nil {
+		browserContext.Close()
+		return nil, fmt.Errorf("failed to create page: %w", err)
+	}
+
+	// Closing the page aborts a navigation in progress
+	done := make(chan struct{})
+	go func() {
+		select {
+		case <-ctx.Done():
+			page.Close()
+		case <-done:
+		}
+	}()
+
+	result, err := p.load(ctx, page, urlStr)
+	close(done)
+	page.Close()
+
+	p.put(browserContext)
+
+	return result, err
+}
+
+// load navigates page to urlStr and returns the rendered document.
+func (p *BrowserPool) load(ctx context.Context, page playwright.Page, urlStr string) (*Page, error) {
+	resp, err := page.Goto(urlStr, playwright.PageGotoOptions{
+		WaitUntil: playwright.WaitUntilStateNetworkidle,
+		Timeout:   playwright.Float(float64(p.Timeout.Milliseconds())),
+	})
+	if ctx.Err() != nil {
+		return nil, ctx.Err()
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to navigate to URL: %w", err)
+	}
+	if err := responseError(resp); err != nil {
+		return nil, err
+	}
+
+	// Get the page content
+	content, err := page.Content()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get page content: %w", err)
+	}
+
+	result := &Page{
+		URL:        page.URL(),
+		StatusCode: http.StatusOK,
+		Header:     http.Header{},
+		Body:       []byte(content),
+	}
+	if resp != nil {
+		result.StatusCode = resp.Status()
+		for key, value := range resp.Headers() {
+			result.Header.Set(key, value)
+		}
+	}
+
+	// Content returns the DOM serialized as UTF-8, whatever the page's
+	// original encoding was
+	result.Header.Set("Content-Type", "text/html; charset=utf-8")
+
+	return result, nil
+}
+
+// responseError returns an HTTPError for a navigation that ended in an error
+// status, as the HTTP fetcher does, so that an error page is not mistaken
+// for the page asked for.
+func responseError(resp playwright.Response) error {
+	if resp == nil || resp.Status() < http.StatusBadRequest {
+		return nil
+	}
+	return &HTTPError{
+		StatusCode: resp.Status(),
+		Status:     fmt.Sprintf("%d %s", resp.Status(), resp.StatusText()),
+	}
+}
+
+// HealthCheck starts the browser if needed and verifies that it can open a
+// blank page.
+func (p *BrowserPool) HealthCheck(ctx context.Context) error {
+	if err := p.acquire(ctx); err != nil {
+		return err
+	}
+	defer p.release()
+
+	browserContext, err := p.context()
+	if err != nil {
+		return err
+	}
+
+	page, err := browserContext.NewPage()
+	if err != nil {
+		browserContext.Close()
+		return fmt.Errorf("browser is not responding: %w", err)
+	}
+	defer page.Close()
+
+	if _, err := page.Goto("about:blank"); err != nil {
+		browserContext.Close()
+		return fmt.Errorf("browser is not responding: %w", err)
+	}
+
+	p.put(browserContext)
+	return nil
+}
+
+// Close waits for open pages to finish, then shuts down the browser and the
+// Playwright driver. Fetches after Close return ErrBrowserClosed.
+func (p *BrowserPool) Close() error {
+	p.mu.Lock()
+	if p.closed {
+		p.mu.Unlock()
+		return nil
+	}
+	p.closed = true
+	if p.done != nil {
+		close(p.done)
+	}
+	slots := p.slots
+	p.mu.Unlock()
+
+	if slots != nil {
+		for i := 0; i < cap(slots); i++ {
+			slots <- struct{}{}
+		}
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	for _, browserContext := range p.idle {
+		browserContext.Close()
+	}
+	p.idle = nil
+
+	var err error
+	if p.browser != nil {
+		err = p.browser.Close()
+		p.browser = nil
+	}
+	if p.pw != nil {
+		if stopErr := p.pw.Stop(); err == nil {
+			err = stopErr
+		}
+		p.pw = nil
+	}
+
+	return err
+}
+
+// acquire waits for a free page slot.
+func (p *BrowserPool) acquire(ctx context.Context) error {
+	p.mu.Lock()
+	if p.closed {
+		p.mu.Unlock()
+		return ErrBrowserClosed
+	}
+	if p.slots == nil {
+		size := p.MaxPages
+		if size < 1 {
+			size = 1
+		}
+		p.slots = make(chan struct{}, size)
+		p.done = make(chan struct{})
+	}
+	slots, done := p.slots, p.done
+	p.mu.Unlock()
+
+	select {
+	case slots <- struct{}{}:
+	case <-done:
+		return ErrBrowserClosed
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+
+	// Close may have run while we waited
+	p.mu.Lock()
+	closed := p.closed
+	p.mu.Unlock()
+	if closed {
+		p.release()
+		return ErrBrowserClosed
+	}
+	return nil
+}
+
+func (p *BrowserPool) release() {
+	<-p.slots
+}
+
+// context returns an idle browser context, or a new one if none is free.
+func (p *BrowserPool) context() (playwright.BrowserContext, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	browser, err := p.launch()
+	if err != nil {
+		return nil, err
+	}
+
+	if n := len(p.idle); n > 0 {
+		browserContext := p.idle[n-1]
+		p.idle = p.idle[:n-1]
+		return browserContext, nil
+	}
+
+	options := playwright.BrowserNewContextOptions{
+		UserAgent: playwright.String(p.UserAgent),
+	}
+	if len(p.Header) > 0 {
+		options.ExtraHttpHeaders = make(map[string]string, len(p.Header))
+		for key := range p.Header {
+			options.ExtraHttpHeaders[key] = strings.Join(p.Header.Values(key), ", ")
+		}
+	}
+
+	browserContext, err := browser.NewContext(options)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create browser context: %w", err)
+	}
+
+	return browserContext, nil
+}
+
+// addCookies adds the configured cookies that match urlStr to a browser
+// context.
+func (p *BrowserPool) addCookies(browserContext playwright.BrowserContext, urlStr string) error {
+	u, err := url.Parse(urlStr)
+	if err != nil {
+		return fmt.Errorf("invalid URL: %w", err)
+	}
+
+	cookies := []playwright.OptionalCookie{}
+	for _, cookie := range p.Cookies {
+		if !cookieMatches(cookie, u) {
+			continue
+		}
+
+		path := cookie.Path
+		if path == "" {
+			path = "/"
+		}
+		cookies = append(cookies, playwright.OptionalCookie{
+			Name:     cookie.Name,
+			Value:    cookie.Value,
+			Domain:   playwright.String("." + strings.TrimPrefix(cookie.Domain, ".")),
+			Path:     playwright.String(path),
+			Secure:   playwright.Bool(cookie.Secure),
+			HttpOnly: playwright.Bool(cookie.HttpOnly),
+		})
+	}
+
+	if len(cookies) == 0 {
+		return nil
+	}
+	if err := browserContext.AddCookies(cookies); err != nil {
+		return fmt.Errorf("failed to add cookies: %w", err)
+	}
+	return nil
+}
+
+// put returns a browser context to the idle list, clearing cookies so that
+// one site's session does not leak into the next fetch.
+func (p *BrowserPool) put(browserContext playwright.BrowserContext) {
+	if err := browserContext.ClearCookies(); err != nil {
+		browserContext.Close()
+		return
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.closed || p.browser == nil || len(p.idle) >= cap(p.slots) {
+		browserContext.Close()
+		return
+	}
+	p.idle = append(p.idle, browserContext)
+}
+
+// launch starts Playwright and Chromium if they are not already running, or
+// restarts Chromium if it has crashed. The caller must hold p.mu.
+func (p *BrowserPool) launch() (playwright.Browser, error) {
+	if p.browser != nil && p.browser.IsConnected() {
+		return p.browser, nil
+	}
+
+	// Contexts of a disconnected browser are unusable
+	p.idle = nil
+
+	if p.pw == nil {
+		pw, err := playwright.Run(&playwright.RunOptions{Verbose: false})
+		if err != nil {
+			return nil, playwrightError("failed to start playwright", err)
+		}
+		p.pw = pw
+	}
+
+	options := playwright.BrowserTypeLaunchOptions{
+		Headless: playwright.Bool(true),
+	}
+	if p.Proxy != "" {
+		proxy, err := playwrightProxy(p.Proxy)
+		if err != nil {
+			return nil, err
+		}
+		options.Proxy = proxy
+	}
+
+	browser, err := p.pw.Chromium.Launch(options)
+	if err != nil {
+		return nil, playwrightError("failed to launch browser", err)
+	}
+	p.browser = browser
+
+	return browser, nil
+}
+
+// playwrightProxy splits the credentials out of a proxy URL, which
+// Chromium takes separately.
+func playwrightProxy(proxyURL string) (*playwright.Proxy, error) {
+	u, err := url.Parse(proxyURL)
+	if err != nil || u.Host == "" {
+		return nil, fmt.Errorf("invalid proxy URL: %s", proxyURL)
+	}
+
+	proxy := &playwright.Proxy{Server: u.Scheme + "://" + u.Host}
+	if u.User != nil {
+		proxy.Username = playwright.String(u.User.Username())
+		if password, ok := u.User.Password(); ok {
+			proxy.Password = playwright.String(password)
+		}
+	}
+	return proxy, nil
+}
+
+// playwrightError wraps err, reporting a missing driver or browser as
+// ErrPlaywrightNotInstalled.
+func playwrightError(msg string, err error) error {
+	text := err.Error()
+	if strings.Contains(text, "please install the driver") ||
+		strings.Contains(text, "Executable doesn't exist") ||
+		strings.Contains(text, "playwright install") {
+		return fmt.Errorf("%s: %w", msg, ErrPlaywrightNotInstalled)
+	}
+	return fmt.Errorf("%s: %w", msg, err)
+}
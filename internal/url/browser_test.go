@@ -0,0 +1,83 @@
+package url
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/playwright-community/playwright-go"
+)
+
+// fakeResponse is a navigation response with a fixed status.
+type fakeResponse struct {
+	playwright.Response
+	status int
+}
+
+func (r *fakeResponse) Status() int {
+	return r.status
+}
+
+func (r *fakeResponse) StatusText() string {
+	return http.StatusText(r.status)
+}
+
+func TestResponseError(t *testing.T) {
+	for _, status := range []int{http.StatusOK, http.StatusNoContent, http.StatusNotModified} {
+		if err := responseError(&fakeResponse{status: status}); err != nil {
+			t.Errorf("responseError(%d) = %v, want nil", status, err)
+		}
+	}
+	if err := responseError(nil); err != nil {
+		t.Errorf("responseError(nil) = %v, want nil", err)
+	}
+
+	// An error page must not be extracted as if it were the page asked for
+	for _, status := range []int{http.StatusNotFound, http.StatusGone, http.StatusServiceUnavailable} {
+		err := responseError(&fakeResponse{status: status})
+		var httpErr *HTTPError
+		if !errors.As(err, &httpErr) || httpErr.StatusCode != status {
+			t.Errorf("responseError(%d) = %v, want an HTTPError with that status", status, err)
+		}
+	}
+}
+
+func TestBrowserPoolClose(t *testing.T) {
+	pool := NewBrowserPool()
+	pool.MaxPages = 1
+
+	// Hold the only slot, so the next fetch has to wait for it
+	if err := pool.acquire(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	waiting := make(chan error)
+	go func() {
+		waiting <- pool.acquire(context.Background())
+	}()
+
+	closed := make(chan error)
+	go func() {
+		closed <- pool.Close()
+	}()
+
+	select {
+	case err := <-waiting:
+		if !errors.Is(err, ErrBrowserClosed) {
+			t.Errorf("acquire() while closing = %v, want ErrBrowserClosed", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("acquire() still waiting after Close")
+	}
+
+	pool.release()
+	if err := <-closed; err != nil {
+		t.Errorf("Close() error = %v", err)
+	}
+
+	if _, err := pool.Fetch(context.Background(), "https://example.com"); !errors.Is(err, ErrBrowserClosed) {
+		t.Errorf("Fetch() after Close error = %v, want ErrBrowserClosed", err)
+	}
+}
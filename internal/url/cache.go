@@ -0,0 +1,171 @@
+package url
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+
+	_ "github.com/mattn/go-sqlite3"
+)
+
+// DefaultCacheTTL is how long a fetched page is served without revalidation.
+const DefaultCacheTTL = 7 * 24 * time.Hour
+
+// Cache stores fetched pages in SQLite, keyed by normalized URL. It can share
+// the database file that holds projects and references.
+type Cache struct {
+	conn *sql.DB
+	TTL  time.Duration
+}
+
+// CachedPage is a page stored in the cache with its HTTP validators.
+type CachedPage struct {
+	Page         *Page
+	ETag         string
+	LastModified string
+	FetchedAt    time.Time
+}
+
+// OpenCache opens (creating if needed) the url_cache table in the SQLite
+// database at path.
+func OpenCache(path string, ttl time.Duration) (*Cache, error) {
+	conn, err := sql.Open("sqlite3", path)
+	if err != nil {
+		return nil, err
+	}
+
+	query := `CREATE TABLE IF NOT EXISTS url_cache (
+		url TEXT PRIMARY KEY,
+		final_url TEXT NOT NULL,
+		status_code INTEGER NOT NULL,
+		headers TEXT,
+		etag TEXT,
+		last_modified TEXT,
+		body BLOB,
+		fetched_at DATETIME NOT NULL
+	)`
+	if _, err := conn.Exec(query); err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("failed to create cache table: %w", err)
+	}
+
+	if ttl <= 0 {
+		ttl = DefaultCacheTTL
+	}
+
+	return &Cache{conn: conn, TTL: ttl}, nil
+}
+
+func (c *Cache) Close() error {
+	return c.conn.Close()
+}
+
+// Get returns the cached copy of a URL, fresh or not.
+func (c *Cache) Get(urlStr string) (*CachedPage, bool) {
+	query := `SELECT final_url, status_code, headers, etag, last_modified, body, fetched_at FROM url_cache WHERE url = ?`
+
+	var (
+		page    Page
+		headers string
+		cached  CachedPage
+	)
+	err := c.conn.QueryRow(query, NormalizeURL(urlStr)).Scan(&page.URL, &page.StatusCode, &headers, &cached.ETag, &cached.LastModified, &page.Body, &cached.FetchedAt)
+	if err != nil {
+		return nil, false
+	}
+
+	page.Header = http.Header{}
+	if headers != "" {
+		json.Unmarshal([]byte(headers), &page.Header)
+	}
+
+	cached.Page = &page
+	return &cached, true
+}
+
+// Expired reports whether a cached page is older than the cache's TTL.
+func (c *Cache) Expired(cached *CachedPage) bool {
+	return time.Since(cached.FetchedAt) > c.TTL
+}
+
+// Put stores a fetched page, replacing any previous copy.
+func (c *Cache) Put(urlStr string, page *Page) error {
+	headers, err := json.Marshal(page.Header)
+	if err != nil {
+		return err
+	}
+
+	query := `INSERT OR REPLACE INTO url_cache (url, final_url, status_code, headers, etag, last_modified, body, fetched_at) VALUES (?, ?, ?, ?, ?, ?, ?, ?)`
+	_, err = c.conn.Exec(query, NormalizeURL(urlStr), page.URL, page.StatusCode, string(headers),
+		page.Header.Get("ETag"), page.Header.Get("Last-Modified"), page.Body, time.Now())
+	if err != nil {
+		return fmt.Errorf("failed to cache page: %w", err)
+	}
+
+	return nil
+}
+
+// Touch marks a cached page as fresh after a successful revalidation.
+func (c *Cache) Touch(urlStr string) error {
+	_, err := c.conn.Exec(`UPDATE url_cache SET fetched_at = ? WHERE url = ?`, time.Now(), NormalizeURL(urlStr))
+	return err
+}
+
+// Prune deletes cached pages fetched more than maxAge ago.
+func (c *Cache) Prune(maxAge time.Duration) (int64, error) {
+	result, err := c.conn.Exec(`DELETE FROM url_cache WHERE fetched_at < ?`, time.Now().Add(-maxAge))
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+// NormalizeURL returns a canonical form of a URL for use as a cache key:
+// lower-case scheme and host, no default port, no fragment and sorted query
+// parameters.
+func NormalizeURL(urlStr string) string {
+	u, err := url.Parse(strings.TrimSpace(urlStr))
+	if err != nil {
+		return urlStr
+	}
+
+	u.Scheme = strings.ToLower(u.Scheme)
+	host := strings.ToLower(u.Hostname())
+	port := u.Port()
+	if (u.Scheme == "http" && port == "80") || (u.Scheme == "https" && port == "443") {
+		port = ""
+	}
+	if port != "" {
+		host = host + ":" + port
+	}
+	u.Host = host
+	u.Fragment = ""
+
+	if u.Path == "" {
+		u.Path = "/"
+	}
+
+	if u.RawQuery != "" {
+		query := u.Query()
+		keys := make([]string, 0, len(query))
+		for key := range query {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+
+		parts := []string{}
+		for _, key := range keys {
+			for _, value := range query[key] {
+				parts = append(parts, url.QueryEscape(key)+"="+url.QueryEscape(value))
+			}
+		}
+		u.RawQuery = strings.Join(parts, "&")
+	}
+
+	return u.String()
+}
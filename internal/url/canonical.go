@@ -0,0 +1,92 @@
+package url
+
+import (
+	"net/url"
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+// trackingParams are query parameters added by analytics and social sites
+// that do not change the page.
+var trackingParams = map[string]bool{
+	"fbclid": true, "gclid": true, "dclid": true, "msclkid": true, "yclid": true,
+	"mc_cid": true, "mc_eid": true, "_ga": true, "_gl": true, "igshid": true,
+	"mkt_tok": true, "ref_src": true, "ref_url": true, "smid": true,
+	"cmpid": true, "ncid": true, "ocid": true, "s_cid": true, "wt_mc": true,
+	"sr_share": true, "__twitter_impression": true,
+}
+
+// StripTracking removes utm_* and other tracking parameters, and text
+// fragments (#:~:text=...), from a URL. Other parameters keep their order.
+func StripTracking(urlStr string) string {
+	u, err := url.Parse(strings.TrimSpace(urlStr))
+	if err != nil {
+		return urlStr
+	}
+
+	if u.RawQuery != "" {
+		kept := []string{}
+		for _, param := range strings.Split(u.RawQuery, "&") {
+			key := param
+			if i := strings.Index(param, "="); i >= 0 {
+				key = param[:i]
+			}
+			key, _ = url.QueryUnescape(key)
+			key = strings.ToLower(key)
+
+			if param == "" || strings.HasPrefix(key, "utm_") || trackingParams[key] {
+				continue
+			}
+			kept = append(kept, param)
+		}
+		u.RawQuery = strings.Join(kept, "&")
+	}
+
+	if strings.HasPrefix(u.Fragment, ":~:") {
+		u.Fragment = ""
+		u.RawFragment = ""
+	}
+
+	return u.String()
+}
+
+// canonicalURL returns the page's canonical URL from <link rel="canonical">
+// or og:url, resolved against the URL it was fetched from, or that URL
+// itself. Tracking parameters are removed either way.
+func canonicalURL(doc *goquery.Document, urlStr string) string {
+	base, err := url.Parse(urlStr)
+	if err != nil {
+		return StripTracking(urlStr)
+	}
+
+	candidates := []string{
+		doc.Find(`link[rel="canonical"]`).First().AttrOr("href", ""),
+		metaContent(doc, `meta[property="og:url"]`),
+	}
+
+	for _, candidate := range candidates {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "" {
+			continue
+		}
+
+		ref, err := url.Parse(candidate)
+		if err != nil {
+			continue
+		}
+		canonical := base.ResolveReference(ref)
+		if canonical.Scheme != "http" && canonical.Scheme != "https" {
+			continue
+		}
+
+		// Some sites point every page at their home page
+		if strings.Trim(canonical.Path, "/") == "" && strings.Trim(base.Path, "/") != "" {
+			continue
+		}
+
+		return StripTracking(canonical.String())
+	}
+
+	return StripTracking(urlStr)
+}
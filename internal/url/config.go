@@ -0,0 +1,205 @@
+package url
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"golang.org/x/net/http/httpproxy"
+)
+
+// FetchConfig configures how pages are fetched, by both the HTTP client and
+// the headless browser. The zero value keeps the defaults.
+type FetchConfig struct {
+	// Proxy is an http://, https:// or socks5:// proxy URL, optionally with
+	// credentials. If empty, HTTPS_PROXY and HTTP_PROXY are used.
+	Proxy string
+
+	UserAgent      string
+	Timeout        time.Duration // per request over plain HTTP
+	BrowserTimeout time.Duration // per page navigation in the browser
+
+	// Header is added to every request, overriding the default headers.
+	Header http.Header
+
+	// Cookies are sent to the sites matching their Domain and Path, for
+	// example an institutional login session for a publisher.
+	Cookies []*http.Cookie
+
+	// RespectRobots skips pages that robots.txt disallows for RobotsAgent,
+	// or DefaultRobotsAgent if that is empty.
+	RespectRobots bool
+	RobotsAgent   string
+}
+
+// ParseCookies reads a Cookie header value ("name=value; name2=value2"), as
+// copied from a browser, into cookies for domain and its subdomains.
+func ParseCookies(domain, header string) ([]*http.Cookie, error) {
+	cookies, err := http.ParseCookie(header)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse cookies: %w", err)
+	}
+
+	domain = strings.TrimPrefix(strings.ToLower(strings.TrimSpace(domain)), ".")
+	for _, cookie := range cookies {
+		cookie.Domain = domain
+		cookie.Path = "/"
+	}
+	return cookies, nil
+}
+
+// ProxyURL returns the configured proxy, or the one from the environment
+// for HTTPS requests, or nil for a direct connection.
+func (c FetchConfig) ProxyURL() (*url.URL, error) {
+	proxy := c.Proxy
+	if proxy == "" {
+		env := httpproxy.FromEnvironment()
+		proxy = env.HTTPSProxy
+		if proxy == "" {
+			proxy = env.HTTPProxy
+		}
+	}
+	if proxy == "" {
+		return nil, nil
+	}
+
+	// "host:port" is an HTTP proxy
+	if !strings.Contains(proxy, "://") {
+		proxy = "http://" + proxy
+	}
+
+	u, err := url.Parse(proxy)
+	if err != nil || u.Host == "" {
+		return nil, fmt.Errorf("invalid proxy URL: %s", proxy)
+	}
+	switch u.Scheme {
+	case "http", "https", "socks5":
+	default:
+		return nil, fmt.Errorf("unsupported proxy scheme: %s", u.Scheme)
+	}
+
+	return u, nil
+}
+
+// HTTPClient returns a client that uses the configured proxy and timeout,
+// for fetching pages and for the identifier resolvers.
+func (c FetchConfig) HTTPClient() (*http.Client, error) {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+
+	if c.Proxy != "" {
+		proxy, err := c.ProxyURL()
+		if err != nil {
+			return nil, err
+		}
+		transport.Proxy = http.ProxyURL(proxy)
+	}
+
+	timeout := c.Timeout
+	if timeout <= 0 {
+		timeout = 10 * time.Second
+	}
+
+	return &http.Client{Transport: transport, Timeout: timeout}, nil
+}
+
+// HTTPFetcher returns an HTTPFetcher using the configuration.
+func (c FetchConfig) HTTPFetcher() (*HTTPFetcher, error) {
+	client, err := c.HTTPClient()
+	if err != nil {
+		return nil, err
+	}
+
+	fetcher := NewHTTPFetcher()
+	fetcher.Client = client
+	fetcher.Header = c.Header
+	fetcher.Cookies = c.Cookies
+	if c.UserAgent != "" {
+		fetcher.UserAgent = c.UserAgent
+	}
+	if c.RespectRobots {
+		fetcher.Robots = c.robots(client)
+	}
+
+	return fetcher, nil
+}
+
+// BrowserPool returns a BrowserPool using the configuration.
+func (c FetchConfig) BrowserPool() (*BrowserPool, error) {
+	pool := NewBrowserPool()
+	pool.Header = c.Header
+	pool.Cookies = c.Cookies
+	if c.UserAgent != "" {
+		pool.UserAgent = c.UserAgent
+	}
+	if c.BrowserTimeout > 0 {
+		pool.Timeout = c.BrowserTimeout
+	}
+
+	proxy, err := c.ProxyURL()
+	if err != nil {
+		return nil, err
+	}
+	if proxy != nil {
+		pool.Proxy = proxy.String()
+	}
+
+	if c.RespectRobots {
+		client, err := c.HTTPClient()
+		if err != nil {
+			return nil, err
+		}
+		pool.Robots = c.robots(client)
+	}
+
+	return pool, nil
+}
+
+// Extractor returns an Extractor like DefaultExtractor's, trying HTTP first
+// and then the browser, with both configured alike. They share one
+// robots.txt cache.
+func (c FetchConfig) Extractor() (*Extractor, error) {
+	fetcher, err := c.HTTPFetcher()
+	if err != nil {
+		return nil, err
+	}
+
+	pool, err := c.BrowserPool()
+	if err != nil {
+		return nil, err
+	}
+	pool.Robots = fetcher.Robots
+
+	return NewExtractor(fetcher, pool), nil
+}
+
+func (c FetchConfig) robots(client *http.Client) *Robots {
+	robots := NewRobots(client)
+	if c.RobotsAgent != "" {
+		robots.Agent = c.RobotsAgent
+	}
+	if c.UserAgent != "" {
+		robots.UserAgent = c.UserAgent
+	}
+	return robots
+}
+
+// cookieMatches reports whether a configured cookie should be sent to u.
+func cookieMatches(cookie *http.Cookie, u *url.URL) bool {
+	host := strings.ToLower(u.Hostname())
+	domain := strings.TrimPrefix(strings.ToLower(cookie.Domain), ".")
+	if domain == "" || (host != domain && !strings.HasSuffix(host, "."+domain)) {
+		return false
+	}
+
+	path := cookie.Path
+	if path == "" {
+		path = "/"
+	}
+	if !strings.HasPrefix(u.Path+"/", strings.TrimSuffix(path, "/")+"/") && path != "/" {
+		return false
+	}
+
+	return !cookie.Secure || u.Scheme == "https"
+}
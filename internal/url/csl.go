@@ -0,0 +1,218 @@
+package url
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/knhn1004/bibtext-to-apa6/internal/bibtex"
+	"github.com/knhn1004/bibtext-to-apa6/internal/doi"
+)
+
+var markupTag = regexp.MustCompile(`<[^>]+>`)
+
+// cslItem is the subset of CSL-JSON returned by DOI registration agencies
+// that is needed to build a reference.
+type cslItem struct {
+	Type            string          `json:"type"`
+	Title           cslString       `json:"title"`
+	ContainerTitle  cslString       `json:"container-title"`
+	CollectionTitle cslString       `json:"collection-title"`
+	Author          []cslName       `json:"author"`
+	Editor          []cslName       `json:"editor"`
+	Translator      []cslName       `json:"translator"`
+	Issued          cslDate         `json:"issued"`
+	PublishedPrint  cslDate         `json:"published-print"`
+	PublishedOnline cslDate         `json:"published-online"`
+	Volume          json.RawMessage `json:"volume"`
+	Issue           json.RawMessage `json:"issue"`
+	Page            string          `json:"page"`
+	ArticleNumber   string          `json:"article-number"`
+	Edition         json.RawMessage `json:"edition"`
+	Number          json.RawMessage `json:"number"`
+	DOI             string          `json:"DOI"`
+	URL             string          `json:"URL"`
+	Publisher       string          `json:"publisher"`
+	PublisherPlace  string          `json:"publisher-place"`
+	ISBN            cslString       `json:"ISBN"`
+	Genre           string          `json:"genre"`
+}
+
+type cslName struct {
+	Family  string `json:"family"`
+	Given   string `json:"given"`
+	Literal string `json:"literal"`
+}
+
+type cslDate struct {
+	DateParts [][]json.Number `json:"date-parts"`
+	Literal   string          `json:"literal"`
+}
+
+// cslString accepts both a plain string and an array of strings, since
+// fields such as container-title are arrays in Crossref output.
+type cslString string
+
+func (s *cslString) UnmarshalJSON(data []byte) error {
+	var str string
+	if err := json.Unmarshal(data, &str); err == nil {
+		*s = cslString(str)
+		return nil
+	}
+
+	var list []string
+	if err := json.Unmarshal(data, &list); err != nil {
+		return err
+	}
+	if len(list) > 0 {
+		*s = cslString(list[0])
+	}
+	return nil
+}
+
+// parseCSL decodes a CSL-JSON item and converts it into a BibTeX entry.
+func parseCSL(data []byte) (*bibtex.Entry, error) {
+	var item cslItem
+	if err := json.Unmarshal(data, &item); err != nil {
+		return nil, fmt.Errorf("failed to parse CSL-JSON: %w", err)
+	}
+
+	return item.toEntry(), nil
+}
+
+func (c *cslItem) toEntry() *bibtex.Entry {
+	entry := &bibtex.Entry{
+		Type:   cslEntryType(c.Type),
+		Fields: make(map[string]string),
+	}
+	if entry.Type == "phdthesis" && strings.Contains(strings.ToLower(c.Genre), "master") {
+		entry.Type = "mastersthesis"
+	}
+
+	set := func(field, value string) {
+		if value = strings.TrimSpace(value); value != "" {
+			entry.Fields[field] = value
+		}
+	}
+
+	set("author", cslNames(c.Author))
+	set("editor", cslNames(c.Editor))
+	set("translator", cslNames(c.Translator))
+	set("title", cleanMarkup(string(c.Title)))
+
+	year := c.Issued.year()
+	if year == "" {
+		year = c.PublishedPrint.year()
+	}
+	if year == "" {
+		year = c.PublishedOnline.year()
+	}
+	set("year", year)
+
+	container := cleanMarkup(string(c.ContainerTitle))
+	switch entry.Type {
+	case "article":
+		set("journal", container)
+	case "incollection", "inproceedings":
+		set("booktitle", container)
+	}
+
+	set("volume", rawString(c.Volume))
+	set("number", rawString(c.Issue))
+	set("pages", c.Page)
+	set("eid", c.ArticleNumber)
+	set("edition", rawString(c.Edition))
+	set("series", cleanMarkup(string(c.CollectionTitle)))
+	set("isbn", string(c.ISBN))
+	set("url", c.URL)
+	if d := doi.Normalize(c.DOI); d != "" {
+		set("doi", d)
+	}
+
+	switch entry.Type {
+	case "phdthesis", "mastersthesis":
+		set("school", c.Publisher)
+	case "techreport":
+		set("institution", c.Publisher)
+		set("number", rawString(c.Number))
+	default:
+		set("publisher", c.Publisher)
+		set("address", c.PublisherPlace)
+	}
+
+	entry.Key = entry.GenerateKey("ref")
+
+	return entry
+}
+
+// cslEntryType maps CSL item types to BibTeX entry types.
+func cslEntryType(cslType string) string {
+	switch cslType {
+	case "article-journal", "article-magazine", "article-newspaper", "review", "review-book":
+		return "article"
+	case "book", "monograph", "edited-book", "reference-book":
+		return "book"
+	case "chapter", "entry", "entry-dictionary", "entry-encyclopedia", "book-chapter":
+		return "incollection"
+	case "paper-conference", "proceedings-article":
+		return "inproceedings"
+	case "thesis", "dissertation":
+		return "phdthesis"
+	case "report":
+		return "techreport"
+	default:
+		return "misc"
+	}
+}
+
+// cslNames joins CSL names in BibTeX "Family, Given and ..." form.
+func cslNames(names []cslName) string {
+	formatted := []string{}
+	for _, n := range names {
+		switch {
+		case n.Family != "" && n.Given != "":
+			formatted = append(formatted, fmt.Sprintf("%s, %s", n.Family, n.Given))
+		case n.Family != "":
+			formatted = append(formatted, n.Family)
+		case n.Literal != "":
+			// Literal names are organizations
+			formatted = append(formatted, bibtex.Name{Family: n.Literal, Corporate: true}.String())
+		}
+	}
+	return strings.Join(formatted, " and ")
+}
+
+func (d cslDate) year() string {
+	if len(d.DateParts) > 0 && len(d.DateParts[0]) > 0 {
+		return d.DateParts[0][0].String()
+	}
+	if len(d.Literal) >= 4 {
+		return extractYearFromDate(d.Literal)
+	}
+	return ""
+}
+
+// rawString reads a CSL value that may be encoded as a string or a number.
+func rawString(raw json.RawMessage) string {
+	if len(raw) == 0 {
+		return ""
+	}
+
+	var s string
+	if err := json.Unmarshal(raw, &s); err == nil {
+		return s
+	}
+
+	var n json.Number
+	if err := json.Unmarshal(raw, &n); err == nil {
+		return n.String()
+	}
+
+	return ""
+}
+
+// cleanMarkup strips the inline HTML/JATS tags that Crossref leaves in titles.
+func cleanMarkup(s string) string {
+	return strings.Join(strings.Fields(markupTag.ReplaceAllString(s, "")), " ")
+}
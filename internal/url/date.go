@@ -0,0 +1,166 @@
+package url
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DatePrecision says how much of a Date is known.
+type DatePrecision int
+
+const (
+	DateUnknown DatePrecision = iota
+	DateYear
+	DateMonth
+	DateDay
+)
+
+// Date is a publication date that may be known only to the year or month.
+type Date struct {
+	Year      int
+	Month     int
+	Day       int
+	Precision DatePrecision
+}
+
+// IsZero reports whether the date is unknown.
+func (d Date) IsZero() bool {
+	return d.Precision == DateUnknown
+}
+
+// String renders the known part of the date as "2021", "2021-03" or
+// "2021-03-03", or "" when the date is unknown.
+func (d Date) String() string {
+	switch d.Precision {
+	case DateYear:
+		return fmt.Sprintf("%04d", d.Year)
+	case DateMonth:
+		return fmt.Sprintf("%04d-%02d", d.Year, d.Month)
+	case DateDay:
+		return fmt.Sprintf("%04d-%02d-%02d", d.Year, d.Month, d.Day)
+	}
+	return ""
+}
+
+// dateLayouts are tried in order; the precision is what the layout contains.
+var dateLayouts = []struct {
+	layout    string
+	precision DatePrecision
+}{
+	{time.RFC3339, DateDay},
+	{"2006-01-02T15:04:05-0700", DateDay},
+	{"2006-01-02T15:04:05.000-0700", DateDay},
+	{"2006-01-02T15:04:05", DateDay},
+	{"2006-01-02T15:04", DateDay},
+	{"2006-01-02 15:04:05", DateDay},
+	{"2006-01-02", DateDay},
+	{"2006/01/02", DateDay},
+	{"2006.01.02", DateDay},
+	{"20060102", DateDay},
+	{time.RFC1123, DateDay},
+	{time.RFC1123Z, DateDay},
+	{time.RFC850, DateDay},
+	{time.ANSIC, DateDay},
+	{"Mon, 2 Jan 2006 15:04:05 MST", DateDay},
+	{"Mon, 2 Jan 2006", DateDay},
+	{"Monday, January 2, 2006", DateDay},
+	{"January 2, 2006", DateDay},
+	{"January 2 2006", DateDay},
+	{"Jan 2, 2006", DateDay},
+	{"Jan. 2, 2006", DateDay},
+	{"2 January 2006", DateDay},
+	{"2 Jan 2006", DateDay},
+	{"January 2006", DateMonth},
+	{"Jan 2006", DateMonth},
+	{"2006-01", DateMonth},
+	{"2006/01", DateMonth},
+	{"2006", DateYear},
+}
+
+var (
+	// slashDatePattern matches numeric dates such as 03/04/2021, whose
+	// day/month order depends on the site's locale.
+	slashDatePattern = regexp.MustCompile(`^(\d{1,2})[/.](\d{1,2})[/.](\d{4})$`)
+
+	// septPattern matches the "Sept" abbreviation, which time.Parse does not
+	// know.
+	septPattern = regexp.MustCompile(`(?i)\bsept\b`)
+
+	// proseDatePattern finds "March 3, 2021" or "3 March 2021" inside text
+	// such as "Published March 3, 2021 at 10:00".
+	proseDatePattern = regexp.MustCompile(`(?i)\b(?:(?:jan|feb|mar|apr|may|jun|jul|aug|sep|oct|nov|dec)[a-z]*\.? \d{1,2},? \d{4}|\d{1,2} (?:jan|feb|mar|apr|may|jun|jul|aug|sep|oct|nov|dec)[a-z]* \d{4})\b`)
+)
+
+// ParseDate parses a date in any of the formats found in page metadata,
+// including ISO 8601, RFC 1123 and "March 3, 2021". It returns the zero Date
+// when no date is recognized.
+func ParseDate(value string) Date {
+	value = septPattern.ReplaceAllString(strings.Join(strings.Fields(value), " "), "Sep")
+	if value == "" {
+		return Date{}
+	}
+
+	for _, l := range dateLayouts {
+		if t, err := time.Parse(l.layout, value); err == nil {
+			return dateFromTime(t, l.precision)
+		}
+	}
+
+	if m := slashDatePattern.FindStringSubmatch(value); m != nil {
+		return parseSlashDate(m[1], m[2], m[3])
+	}
+
+	if m := proseDatePattern.FindString(value); m != "" && m != value {
+		if d := ParseDate(m); !d.IsZero() {
+			return d
+		}
+	}
+
+	// Fall back to a leading year, as in "2021-03-03 (updated)"
+	if len(value) >= 4 {
+		if year, err := strconv.Atoi(value[:4]); err == nil && year > 0 {
+			return Date{Year: year, Precision: DateYear}
+		}
+	}
+
+	return Date{}
+}
+
+// extractYearFromDate returns just the year of a date string.
+func extractYearFromDate(dateStr string) string {
+	date := ParseDate(dateStr)
+	if date.IsZero() {
+		return ""
+	}
+	return strconv.Itoa(date.Year)
+}
+
+func dateFromTime(t time.Time, precision DatePrecision) Date {
+	d := Date{Year: t.Year(), Precision: precision}
+	if precision >= DateMonth {
+		d.Month = int(t.Month())
+	}
+	if precision >= DateDay {
+		d.Day = t.Day()
+	}
+	return d
+}
+
+// parseSlashDate resolves the day and month of a numeric date when only one
+// order is possible, and keeps just the year when it is ambiguous.
+func parseSlashDate(first, second, year string) Date {
+	a, _ := strconv.Atoi(first)
+	b, _ := strconv.Atoi(second)
+	y, _ := strconv.Atoi(year)
+
+	switch {
+	case a > 12 && a <= 31 && b >= 1 && b <= 12:
+		return Date{Year: y, Month: b, Day: a, Precision: DateDay}
+	case b > 12 && b <= 31 && a >= 1 && a <= 12:
+		return Date{Year: y, Month: a, Day: b, Precision: DateDay}
+	}
+	return Date{Year: y, Precision: DateYear}
+}
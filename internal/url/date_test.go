@@ -0,0 +1,103 @@
+package url
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseDate(t *testing.T) {
+	tests := []struct {
+		value string
+		want  string
+	}{
+		// ISO 8601 in the forms found in article:published_time
+		{"2021-03-03T10:00:00Z", "2021-03-03"},
+		{"2021-03-03T10:00:00+01:00", "2021-03-03"},
+		{"2021-03-03T10:00:00+0000", "2021-03-03"},
+		{"2021-03-03T10:00:00.000-0500", "2021-03-03"},
+		{"2021-03-03T10:00:00.123456Z", "2021-03-03"},
+		{"2021-03-03T10:00", "2021-03-03"},
+		{"2021-03-03 10:00:00", "2021-03-03"},
+		{"2021-03-03", "2021-03-03"},
+		{"2021/03/03", "2021-03-03"},
+		{"20210303", "2021-03-03"},
+
+		// HTTP and prose dates
+		{"Wed, 03 Mar 2021 10:00:00 GMT", "2021-03-03"},
+		{"Wednesday, March 3, 2021", "2021-03-03"},
+		{"March 3, 2021", "2021-03-03"},
+		{"Mar. 3, 2021", "2021-03-03"},
+		{"Sept. 14, 2022", "2022-09-14"},
+		{"3 March 2021", "2021-03-03"},
+		{"  March   3,  2021 ", "2021-03-03"},
+		{"Published March 3, 2021 at 10:00", "2021-03-03"},
+
+		// Partial dates keep only what is known
+		{"March 2021", "2021-03"},
+		{"2021-03", "2021-03"},
+		{"2021", "2021"},
+		{"2021-03-03 (updated)", "2021"},
+
+		// Numeric dates whose order is clear, and one that is not
+		{"25/12/2021", "2021-12-25"},
+		{"12/25/2021", "2021-12-25"},
+		{"25.12.2021", "2021-12-25"},
+		{"03/04/2021", "2021"},
+
+		// No date, cited as n.d.
+		{"", ""},
+		{"n.d.", ""},
+		{"unknown", ""},
+	}
+
+	for _, tt := range tests {
+		if got := ParseDate(tt.value).String(); got != tt.want {
+			t.Errorf("ParseDate(%q) = %q, want %q", tt.value, got, tt.want)
+		}
+	}
+}
+
+func TestParseSlashDate(t *testing.T) {
+	tests := []struct {
+		first, second, year string
+		want                Date
+	}{
+		{"25", "12", "2021", Date{Year: 2021, Month: 12, Day: 25, Precision: DateDay}},
+		{"12", "25", "2021", Date{Year: 2021, Month: 12, Day: 25, Precision: DateDay}},
+		{"3", "4", "2021", Date{Year: 2021, Precision: DateYear}},
+		{"12", "12", "2021", Date{Year: 2021, Precision: DateYear}},
+		{"32", "12", "2021", Date{Year: 2021, Precision: DateYear}},
+		{"13", "13", "2021", Date{Year: 2021, Precision: DateYear}},
+	}
+
+	for _, tt := range tests {
+		if got := parseSlashDate(tt.first, tt.second, tt.year); got != tt.want {
+			t.Errorf("parseSlashDate(%s, %s, %s) = %+v, want %+v", tt.first, tt.second, tt.year, got, tt.want)
+		}
+	}
+}
+
+func TestUndatedPage(t *testing.T) {
+	metadata := &Metadata{
+		Type:       TypeWebPage,
+		Title:      "All about autism",
+		Authors:    ParseByline("National Autism Association"),
+		Date:       ParseDate(""),
+		URL:        "https://example.com/about-autism",
+		AccessDate: time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC),
+	}
+
+	entry := metadata.ToEntry()
+	if year := entry.GetField("year"); year != "" {
+		t.Errorf("year = %q, want none", year)
+	}
+
+	apa, err := metadata.ToAPAFormat()
+	if err != nil {
+		t.Fatalf("ToAPAFormat() error = %v", err)
+	}
+	if !strings.Contains(apa, "(n.d.)") || !strings.Contains(apa, "Retrieved May 1, 2024, from") {
+		t.Errorf("ToAPAFormat() = %q, want n.d. with a retrieval date", apa)
+	}
+}
@@ -0,0 +1,94 @@
+package url
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/knhn1004/bibtext-to-apa6/internal/bibtex"
+	"github.com/knhn1004/bibtext-to-apa6/internal/doi"
+)
+
+// DefaultDOIBaseURL is the DOI proxy that redirects to the registration
+// agency (Crossref, DataCite, mEDRA) holding a DOI's metadata.
+const DefaultDOIBaseURL = "https://doi.org"
+
+// DOIResolver looks up DOIs through content negotiation, returning the
+// registration agency's own metadata rather than scraped meta tags.
+type DOIResolver struct {
+	BaseURL string
+	Client  *http.Client
+}
+
+// NewDOIResolver returns a resolver that queries doi.org.
+func NewDOIResolver() *DOIResolver {
+	return &DOIResolver{
+		BaseURL: DefaultDOIBaseURL,
+		Client:  &http.Client{Timeout: 15 * time.Second},
+	}
+}
+
+// Resolve fetches the metadata for a DOI, given in any form accepted by
+// doi.Parse, and converts it into a BibTeX entry.
+func (r *DOIResolver) Resolve(ctx context.Context, doiStr string) (*bibtex.Entry, error) {
+	d, err := doi.Parse(doiStr)
+	if err != nil {
+		return nil, err
+	}
+
+	baseURL := strings.TrimRight(r.BaseURL, "/")
+	if baseURL == "" {
+		baseURL = DefaultDOIBaseURL
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "GET", baseURL+"/"+doi.Escape(d), nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	// Prefer CSL-JSON, which every agency supports, and accept BibTeX
+	req.Header.Set("Accept", "application/vnd.citationstyles.csl+json, application/x-bibtex;q=0.5")
+
+	client := r.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve DOI: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, fmt.Errorf("DOI not found: %s", d)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("HTTP error: %s", resp.Status)
+	}
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxBodySize))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response: %w", err)
+	}
+
+	var entry *bibtex.Entry
+	if strings.Contains(resp.Header.Get("Content-Type"), "bibtex") {
+		entry, err = bibtex.Parse(string(body))
+	} else {
+		entry, err = parseCSL(body)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	// Agencies occasionally omit the DOI that was asked for
+	if entry.GetField("doi") == "" {
+		entry.Fields["doi"] = d
+	}
+
+	return entry, nil
+}
@@ -0,0 +1,168 @@
+package url
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// newDOIServer stands in for doi.org, serving the CSL-JSON and BibTeX
+// records in testdata/doi by DOI suffix.
+func newDOIServer(t *testing.T) (*httptest.Server, *[]string) {
+	t.Helper()
+
+	accepts := []string{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		accepts = append(accepts, r.Header.Get("Accept"))
+
+		switch r.URL.Path {
+		case "/10.1111/jsr.13250":
+			serveFixture(t, w, "doi/article.json", "application/vnd.citationstyles.csl+json")
+		case "/10.1016/B978-0-12-800000-0.00005-1":
+			serveFixture(t, w, "doi/chapter.json", "application/vnd.citationstyles.csl+json; charset=utf-8")
+		case "/10.5281/zenodo.1234567":
+			serveFixture(t, w, "doi/thesis.json", "application/vnd.citationstyles.csl+json")
+		case "/10.5281/zenodo.7654321":
+			serveFixture(t, w, "doi/dataset.bib", "application/x-bibtex")
+		case "/10.1000/broken":
+			w.Header().Set("Content-Type", "application/vnd.citationstyles.csl+json")
+			w.Write([]byte(`{"type": "article-journal", "title": `))
+		case "/10.1000/unavailable":
+			w.WriteHeader(http.StatusServiceUnavailable)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(server.Close)
+
+	return server, &accepts
+}
+
+// serveFixture writes a testdata file with the given Content-Type.
+func serveFixture(t *testing.T, w http.ResponseWriter, name, contentType string) {
+	body, err := os.ReadFile(filepath.Join("testdata", name))
+	if err != nil {
+		t.Errorf("failed to read fixture: %v", err)
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", contentType)
+	w.Write(body)
+}
+
+func TestDOIResolver(t *testing.T) {
+	server, accepts := newDOIServer(t)
+	resolver := &DOIResolver{BaseURL: server.URL + "/"}
+
+	tests := []struct {
+		doi      string
+		wantType string
+		want     map[string]string
+	}{
+		{
+			doi:      "https://doi.org/10.1111/jsr.13250",
+			wantType: "article",
+			want: map[string]string{
+				"author":    "Garcia, Maria and Chen, Wei and {Sleep Study Consortium}",
+				"title":     "Effects of sleep on memory consolidation",
+				"journal":   "Journal of Sleep Research",
+				"year":      "2021",
+				"volume":    "30",
+				"number":    "4",
+				"pages":     "e13250",
+				"eid":       "e13250",
+				"doi":       "10.1111/JSR.13250",
+				"url":       "https://doi.org/10.1111/jsr.13250",
+				"publisher": "Wiley",
+			},
+		},
+		{
+			doi:      "doi:10.1016/B978-0-12-800000-0.00005-1",
+			wantType: "incollection",
+			want: map[string]string{
+				"author":    "Meyer, Paul",
+				"editor":    "Baker, Ruth and Ng, Tom",
+				"title":     "Measuring attention",
+				"booktitle": "Handbook of Cognitive Methods",
+				"year":      "2019",
+				"pages":     "101-130",
+				"edition":   "2",
+				"isbn":      "9780128000000",
+				"publisher": "Academic Press",
+				"address":   "London",
+				"doi":       "10.1016/B978-0-12-800000-0.00005-1",
+			},
+		},
+		{
+			doi:      "10.5281/zenodo.1234567",
+			wantType: "mastersthesis",
+			want: map[string]string{
+				"author": "Fischer, Lena",
+				"title":  "Urban heat islands in mid-sized cities",
+				"year":   "2018",
+				"school": "University of Oslo",
+				"doi":    "10.5281/zenodo.1234567",
+			},
+		},
+		{
+			// BibTeX responses without a DOI get the one that was asked for
+			doi:      "10.5281/zenodo.7654321",
+			wantType: "misc",
+			want: map[string]string{
+				"author":    "Hansen, Erik",
+				"title":     "Coastal temperature records",
+				"year":      "2020",
+				"publisher": "Zenodo",
+				"doi":       "10.5281/zenodo.7654321",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.doi, func(t *testing.T) {
+			entry, err := resolver.Resolve(context.Background(), tt.doi)
+			if err != nil {
+				t.Fatalf("Resolve() error = %v", err)
+			}
+			if entry.Type != tt.wantType {
+				t.Errorf("Type = %q, want %q", entry.Type, tt.wantType)
+			}
+			if !reflect.DeepEqual(entry.Fields, tt.want) {
+				t.Errorf("Fields = %v, want %v", entry.Fields, tt.want)
+			}
+		})
+	}
+
+	for _, accept := range *accepts {
+		if !strings.HasPrefix(accept, "application/vnd.citationstyles.csl+json") || !strings.Contains(accept, "application/x-bibtex") {
+			t.Errorf("Accept = %q, want CSL-JSON preferred over BibTeX", accept)
+		}
+	}
+}
+
+func TestDOIResolverErrors(t *testing.T) {
+	server, _ := newDOIServer(t)
+	resolver := &DOIResolver{BaseURL: server.URL}
+
+	tests := []struct {
+		doi     string
+		wantErr string
+	}{
+		{"10.1000/missing", "DOI not found: 10.1000/missing"},
+		{"10.1000/unavailable", "503"},
+		{"10.1000/broken", "failed to parse CSL-JSON"},
+		{"not a doi", "invalid DOI"},
+	}
+
+	for _, tt := range tests {
+		_, err := resolver.Resolve(context.Background(), tt.doi)
+		if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+			t.Errorf("Resolve(%q) error = %v, want %q", tt.doi, err, tt.wantErr)
+		}
+	}
+}
@@ -0,0 +1,103 @@
+package url
+
+import (
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/knhn1004/bibtext-to-apa6/internal/bibtex"
+)
+
+// ToEntry converts extracted metadata into a BibTeX entry, so that URL
+// references can be stored, exported and formatted like BibTeX ones.
+func (m *Metadata) ToEntry() *bibtex.Entry {
+	entry := &bibtex.Entry{
+		Type:   entryType(m),
+		Fields: make(map[string]string),
+	}
+
+	set := func(field, value string) {
+		if value = strings.TrimSpace(value); value != "" {
+			entry.Fields[field] = value
+		}
+	}
+
+	set("author", bibtex.FormatNames(m.Authors))
+	set("title", m.Title)
+	if !m.Date.IsZero() {
+		set("year", strconv.Itoa(m.Date.Year))
+	}
+	if m.Date.Precision >= DateMonth {
+		set("month", strconv.Itoa(m.Date.Month))
+	}
+	if m.Date.Precision >= DateDay {
+		set("day", strconv.Itoa(m.Date.Day))
+	}
+	set("doi", m.DOI)
+	switch m.Type {
+	case TypeWiki:
+		set("entrysubtype", "wiki")
+	case TypeNewsArticle:
+		set("entrysubtype", "newspaper")
+	case TypeBlogPost:
+		set("entrysubtype", "blog")
+	case TypeSocialPost:
+		set("entrysubtype", "social")
+		set("nameaddon", m.ScreenName)
+	}
+	set("url", m.URL)
+	if !m.AccessDate.IsZero() {
+		set("urldate", m.AccessDate.Format("2006-01-02"))
+	}
+
+	if entry.Type == "article" {
+		set("journal", m.container())
+		set("volume", m.Volume)
+		set("number", m.Issue)
+		set("publisher", m.Publisher)
+		if m.FirstPage != "" && m.LastPage != "" && m.FirstPage != m.LastPage {
+			set("pages", fmt.Sprintf("%s--%s", m.FirstPage, m.LastPage))
+		} else {
+			set("pages", m.FirstPage)
+		}
+	} else {
+		// The site acts as group author when the page names no author
+		set("organization", m.Publisher)
+	}
+
+	host := "web"
+	if u, err := url.Parse(m.URL); err == nil && u.Hostname() != "" {
+		host = strings.TrimPrefix(u.Hostname(), "www.")
+	}
+	entry.Key = entry.GenerateKey(host)
+
+	return entry
+}
+
+func entryType(m *Metadata) string {
+	switch m.Type {
+	case TypeJournalArticle, TypeNewsArticle:
+		if m.container() != "" {
+			return "article"
+		}
+		return "online"
+	case TypeWebPage, TypeArticle, TypeBlogPost, TypeSocialPost, TypeWiki:
+		return "online"
+	case TypeVideo:
+		return "video"
+	case TypeSoftware:
+		return "software"
+	default:
+		return "misc"
+	}
+}
+
+// container returns the journal or newspaper an article appeared in. Online
+// news outlets often name themselves only as the publisher.
+func (m *Metadata) container() string {
+	if m.Journal == "" && m.Type == TypeNewsArticle {
+		return m.Publisher
+	}
+	return m.Journal
+}
@@ -0,0 +1,290 @@
+package url
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+const defaultUserAgent = "Mozilla/5.0 (Macintosh; Intel Mac OS X 10_15_7) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/120.0.0.0 Safari/537.36"
+
+// maxBodySize caps how much of a page is read; metadata lives in the head.
+const maxBodySize = 10 << 20
+
+// ErrNotModified is returned by FetchIfModified when the cached copy of a page
+// is still current.
+var ErrNotModified = errors.New("not modified")
+
+// HTTPError reports a response with an unexpected status code.
+type HTTPError struct {
+	StatusCode int
+	Status     string
+}
+
+func (e *HTTPError) Error() string {
+	return fmt.Sprintf("HTTP error: %s", e.Status)
+}
+
+// Page is a fetched HTML document.
+type Page struct {
+	URL        string // final URL after redirects
+	StatusCode int
+	Header     http.Header
+	Body       []byte
+}
+
+// Fetcher retrieves the HTML of a page.
+type Fetcher interface {
+	Fetch(ctx context.Context, urlStr string) (*Page, error)
+}
+
+// HTTPFetcher fetches pages with a plain HTTP client and browser-like headers.
+type HTTPFetcher struct {
+	Client    *http.Client
+	UserAgent string
+
+	// Header overrides or adds request headers, and Cookies are sent to
+	// the sites they match.
+	Header  http.Header
+	Cookies []*http.Cookie
+
+	// Robots, if set, refuses pages disallowed by robots.txt.
+	Robots *Robots
+}
+
+// NewHTTPFetcher returns an HTTPFetcher with a 10 second timeout.
+func NewHTTPFetcher() *HTTPFetcher {
+	return &HTTPFetcher{
+		Client:    &http.Client{Timeout: 10 * time.Second},
+		UserAgent: defaultUserAgent,
+	}
+}
+
+func (f *HTTPFetcher) Fetch(ctx context.Context, urlStr string) (*Page, error) {
+	return f.fetch(ctx, urlStr, nil)
+}
+
+// FetchIfModified revalidates a cached page with its ETag and Last-Modified
+// validators, returning ErrNotModified if the server reports no change.
+func (f *HTTPFetcher) FetchIfModified(ctx context.Context, urlStr, etag, lastModified string) (*Page, error) {
+	header := http.Header{}
+	if etag != "" {
+		header.Set("If-None-Match", etag)
+	}
+	if lastModified != "" {
+		header.Set("If-Modified-Since", lastModified)
+	}
+	return f.fetch(ctx, urlStr, header)
+}
+
+func (f *HTTPFetcher) fetch(ctx context.Context, urlStr string, extra http.Header) (*Page, error) {
+	if f.Robots != nil {
+		if err := f.Robots.Check(ctx, urlStr); err != nil {
+			return nil, err
+		}
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "GET", urlStr, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	// Set headers to mimic a browser request
+	req.Header.Set("User-Agent", f.UserAgent)
+	req.Header.Set("Accept", "text/html,application/xhtml+xml,application/xml;q=0.9,image/avif,image/webp,image/apng,*/*;q=0.8")
+	req.Header.Set("Accept-Language", "en-US,en;q=0.9")
+	for key, values := range f.Header {
+		req.Header[http.CanonicalHeaderKey(key)] = values
+	}
+	for key, values := range extra {
+		req.Header[key] = values
+	}
+	for _, cookie := range f.Cookies {
+		if cookieMatches(cookie, req.URL) {
+			req.AddCookie(cookie)
+		}
+	}
+
+	client := f.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch URL: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotModified {
+		return nil, ErrNotModified
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, &HTTPError{StatusCode: resp.StatusCode, Status: resp.Status}
+	}
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxBodySize))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response: %w", err)
+	}
+
+	return &Page{
+		URL:        resp.Request.URL.String(),
+		StatusCode: resp.StatusCode,
+		Header:     resp.Header,
+		Body:       body,
+	}, nil
+}
+
+// Extractor fetches pages with a list of fetchers, trying each in turn until
+// one succeeds, and extracts their metadata.
+type Extractor struct {
+	Fetchers []Fetcher
+
+	// Cache, if set, serves recently fetched pages without a network round
+	// trip. NoCache bypasses cached copies but still stores fresh ones.
+	Cache   *Cache
+	NoCache bool
+}
+
+// NewExtractor returns an Extractor that tries the given fetchers in order.
+func NewExtractor(fetchers ...Fetcher) *Extractor {
+	return &Extractor{Fetchers: fetchers}
+}
+
+// DefaultExtractor tries HTTP first and falls back to a headless browser.
+func DefaultExtractor() *Extractor {
+	return NewExtractor(NewHTTPFetcher(), NewBrowserPool())
+}
+
+// Close releases resources held by fetchers, such as a running browser.
+func (e *Extractor) Close() error {
+	var firstErr error
+	for _, fetcher := range e.Fetchers {
+		if closer, ok := fetcher.(io.Closer); ok {
+			if err := closer.Close(); err != nil && firstErr == nil {
+				firstErr = err
+			}
+		}
+	}
+	return firstErr
+}
+
+// Extract fetches urlStr and returns its metadata.
+func (e *Extractor) Extract(ctx context.Context, urlStr string) (*Metadata, error) {
+	metadata, _, err := e.ExtractPage(ctx, urlStr)
+	return metadata, err
+}
+
+// ExtractPage is like Extract but also returns the fetched page, so that it
+// can be kept as a snapshot of what the metadata was extracted from.
+func (e *Extractor) ExtractPage(ctx context.Context, urlStr string) (*Metadata, *Page, error) {
+	if _, err := url.Parse(urlStr); err != nil {
+		return nil, nil, fmt.Errorf("invalid URL: %w", err)
+	}
+
+	page, err := e.Fetch(ctx, urlStr)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	metadata, err := ParsePage(page, urlStr)
+	if err != nil {
+		return nil, nil, err
+	}
+	return metadata, page, nil
+}
+
+// Fetch returns the page from the cache if it is fresh, or else from the
+// first fetcher that succeeds. An expired copy is still returned when the
+// page cannot be fetched at all, as when working offline.
+func (e *Extractor) Fetch(ctx context.Context, urlStr string) (*Page, error) {
+	if e.Cache == nil {
+		return e.fetch(ctx, urlStr)
+	}
+
+	var stale *CachedPage
+	if !e.NoCache {
+		if cached, ok := e.Cache.Get(urlStr); ok {
+			if !e.Cache.Expired(cached) {
+				return cached.Page, nil
+			}
+			if page, err := e.revalidate(ctx, urlStr, cached); err == nil {
+				return page, nil
+			}
+			stale = cached
+		}
+	}
+
+	page, err := e.fetch(ctx, urlStr)
+	if err != nil {
+		if stale != nil && ctx.Err() == nil {
+			return stale.Page, nil
+		}
+		return nil, err
+	}
+
+	// A cache that cannot be written, such as on a full disk, only costs a
+	// refetch next time
+	e.Cache.Put(urlStr, page)
+
+	return page, nil
+}
+
+// revalidate asks the server whether a stale cached page has changed, using
+// the first fetcher that supports conditional requests.
+func (e *Extractor) revalidate(ctx context.Context, urlStr string, cached *CachedPage) (*Page, error) {
+	if cached.ETag == "" && cached.LastModified == "" {
+		return nil, fmt.Errorf("no validators for cached page")
+	}
+
+	for _, fetcher := range e.Fetchers {
+		conditional, ok := fetcher.(interface {
+			FetchIfModified(ctx context.Context, urlStr, etag, lastModified string) (*Page, error)
+		})
+		if !ok {
+			continue
+		}
+
+		page, err := conditional.FetchIfModified(ctx, urlStr, cached.ETag, cached.LastModified)
+		if errors.Is(err, ErrNotModified) {
+			e.Cache.Touch(urlStr)
+			return cached.Page, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		e.Cache.Put(urlStr, page)
+		return page, nil
+	}
+
+	return nil, fmt.Errorf("no fetcher supports revalidation")
+}
+
+func (e *Extractor) fetch(ctx context.Context, urlStr string) (*Page, error) {
+	if len(e.Fetchers) == 0 {
+		return nil, fmt.Errorf("no fetchers configured")
+	}
+
+	// Every fetcher's error is kept, so that a definite HTTPError such as a
+	// 404 from the HTTP fetcher is not hidden by a later browser failure
+	var errs []error
+	for _, fetcher := range e.Fetchers {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		page, err := fetcher.Fetch(ctx, urlStr)
+		if err == nil {
+			return page, nil
+		}
+		errs = append(errs, err)
+	}
+
+	return nil, errors.Join(errs...)
+}
@@ -0,0 +1,396 @@
+package url
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// newFixtureServer serves the pages in testdata/pages at /{name}, and
+// redirects /old/{name} to them.
+func newFixtureServer(t *testing.T) *httptest.Server {
+	t.Helper()
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/old/", func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, "/"+strings.TrimPrefix(r.URL.Path, "/old/"), http.StatusMovedPermanently)
+	})
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		body, err := os.ReadFile(filepath.Join("testdata", "pages", strings.TrimPrefix(r.URL.Path, "/")+".html"))
+		if err != nil {
+			http.NotFound(w, r)
+			return
+		}
+
+		w.Header().Set("ETag", `"v1"`)
+		if r.Header.Get("If-None-Match") == `"v1"` {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		w.Write(body)
+	})
+
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestHTTPFetcher(t *testing.T) {
+	var got http.Header
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = r.Header.Clone()
+		w.Write([]byte("<html></html>"))
+	}))
+	defer server.Close()
+
+	fetcher := NewHTTPFetcher()
+	fetcher.Header = http.Header{"X-Test": {"yes"}}
+	fetcher.Cookies = []*http.Cookie{{Name: "session", Value: "abc", Domain: "127.0.0.1"}, {Name: "other", Value: "x", Domain: "example.com"}}
+
+	page, err := fetcher.Fetch(context.Background(), server.URL)
+	if err != nil {
+		t.Fatalf("Fetch() error = %v", err)
+	}
+	if string(page.Body) != "<html></html>" || page.StatusCode != http.StatusOK {
+		t.Errorf("Fetch() = %d %q", page.StatusCode, page.Body)
+	}
+
+	if ua := got.Get("User-Agent"); ua != defaultUserAgent {
+		t.Errorf("User-Agent = %q, want the default", ua)
+	}
+	if got.Get("X-Test") != "yes" {
+		t.Errorf("X-Test header not sent")
+	}
+	if cookie := got.Get("Cookie"); cookie != "session=abc" {
+		t.Errorf("Cookie = %q, want session=abc", cookie)
+	}
+}
+
+func TestHTTPFetcherRedirect(t *testing.T) {
+	server := newFixtureServer(t)
+
+	page, err := NewHTTPFetcher().Fetch(context.Background(), server.URL+"/old/article")
+	if err != nil {
+		t.Fatalf("Fetch() error = %v", err)
+	}
+	if want := server.URL + "/article"; page.URL != want {
+		t.Errorf("Page.URL = %q, want %q", page.URL, want)
+	}
+	if !strings.Contains(string(page.Body), "Rivers of the Pacific Northwest") {
+		t.Errorf("Fetch() returned the wrong page")
+	}
+}
+
+func TestHTTPFetcherNotFound(t *testing.T) {
+	server := newFixtureServer(t)
+
+	_, err := NewHTTPFetcher().Fetch(context.Background(), server.URL+"/missing")
+
+	var httpErr *HTTPError
+	if !errors.As(err, &httpErr) || httpErr.StatusCode != http.StatusNotFound {
+		t.Errorf("Fetch() error = %v, want an HTTPError with status 404", err)
+	}
+}
+
+func TestHTTPFetcherIfModified(t *testing.T) {
+	server := newFixtureServer(t)
+	fetcher := NewHTTPFetcher()
+
+	if _, err := fetcher.FetchIfModified(context.Background(), server.URL+"/article", `"v1"`, ""); !errors.Is(err, ErrNotModified) {
+		t.Errorf("FetchIfModified() with a current ETag error = %v, want ErrNotModified", err)
+	}
+	if _, err := fetcher.FetchIfModified(context.Background(), server.URL+"/article", `"v0"`, ""); err != nil {
+		t.Errorf("FetchIfModified() with a stale ETag error = %v", err)
+	}
+}
+
+func TestHTTPFetcherCanceled(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-r.Context().Done():
+		case <-time.After(5 * time.Second):
+		}
+	}))
+	defer server.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	if _, err := NewHTTPFetcher().Fetch(ctx, server.URL); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Fetch() error = %v, want context.DeadlineExceeded", err)
+	}
+}
+
+// failingFetcher is a Fetcher that always fails.
+type failingFetcher struct {
+	err   error
+	calls int
+}
+
+func (f *failingFetcher) Fetch(ctx context.Context, urlStr string) (*Page, error) {
+	f.calls++
+	return nil, f.err
+}
+
+func TestExtractor(t *testing.T) {
+	server := newFixtureServer(t)
+
+	tests := []struct {
+		page      string
+		wantType  string
+		wantTitle string
+		wantURL   string
+		wantAPA   string
+	}{
+		{
+			page:      "article",
+			wantType:  TypeArticle,
+			wantTitle: "Rivers of the Pacific Northwest",
+			wantURL:   server.URL + "/articles/rivers",
+			wantAPA:   "Lovelace, A. (2022, September 14). *Rivers of the pacific northwest*. Field Notes. Retrieved from " + server.URL + "/articles/rivers",
+		},
+		{
+			page:      "journal",
+			wantType:  TypeJournalArticle,
+			wantTitle: "Sediment transport in braided rivers",
+			wantURL:   server.URL + "/journal",
+			wantAPA:   "Smith, J., & Doe, J. (2020). Sediment transport in braided rivers. *Journal of Hydrology*, *12*(3), 45–67. https://doi.org/10.1016/j.jhydrol.2020.01.001",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.page, func(t *testing.T) {
+			first := &failingFetcher{err: errors.New("blocked")}
+			extractor := NewExtractor(first, NewHTTPFetcher())
+
+			metadata, err := extractor.Extract(context.Background(), server.URL+"/"+tt.page)
+			if err != nil {
+				t.Fatalf("Extract() error = %v", err)
+			}
+			if first.calls != 1 {
+				t.Errorf("first fetcher called %d times, want 1", first.calls)
+			}
+
+			if metadata.Type != tt.wantType {
+				t.Errorf("Type = %q, want %q", metadata.Type, tt.wantType)
+			}
+			if metadata.Title != tt.wantTitle {
+				t.Errorf("Title = %q, want %q", metadata.Title, tt.wantTitle)
+			}
+			if metadata.URL != tt.wantURL {
+				t.Errorf("URL = %q, want %q", metadata.URL, tt.wantURL)
+			}
+
+			apa, err := metadata.ToAPAFormat()
+			if err != nil {
+				t.Fatalf("ToAPAFormat() error = %v", err)
+			}
+			if apa != tt.wantAPA {
+				t.Errorf("ToAPAFormat() =\n%s\nwant\n%s", apa, tt.wantAPA)
+			}
+		})
+	}
+}
+
+func TestExtractorErrors(t *testing.T) {
+	if _, err := NewExtractor().Extract(context.Background(), "https://example.com"); err == nil {
+		t.Errorf("Extract() with no fetchers succeeded")
+	}
+
+	want := errors.New("offline")
+	_, err := NewExtractor(&failingFetcher{err: want}).Extract(context.Background(), "https://example.com")
+	if !errors.Is(err, want) {
+		t.Errorf("Extract() error = %v, want %v", err, want)
+	}
+}
+
+func TestExtractMetadata(t *testing.T) {
+	server := newFixtureServer(t)
+
+	metadata, err := ExtractMetadata(server.URL + "/article")
+	if err != nil {
+		t.Fatalf("ExtractMetadata() error = %v", err)
+	}
+
+	if len(metadata.Authors) != 1 || metadata.Authors[0].Family != "Lovelace" {
+		t.Errorf("Authors = %v, want Lovelace", metadata.Authors)
+	}
+	if metadata.Date.Year != 2022 || metadata.Date.Month != 9 || metadata.Date.Day != 14 {
+		t.Errorf("Date = %v, want 2022-09-14", metadata.Date)
+	}
+	if metadata.Publisher != "Field Notes" {
+		t.Errorf("Publisher = %q, want Field Notes", metadata.Publisher)
+	}
+}
+
+func TestExtractorCache(t *testing.T) {
+	server := newFixtureServer(t)
+
+	cache, err := OpenCache(filepath.Join(t.TempDir(), "cache.db"), time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cache.Close()
+
+	fetcher := &countingFetcher{Fetcher: NewHTTPFetcher()}
+	extractor := NewExtractor(fetcher)
+	extractor.Cache = cache
+
+	for i := 0; i < 2; i++ {
+		if _, err := extractor.Fetch(context.Background(), server.URL+"/article"); err != nil {
+			t.Fatalf("Fetch() error = %v", err)
+		}
+	}
+	if fetcher.calls != 1 {
+		t.Errorf("fetcher called %d times, want 1 with a fresh cached copy", fetcher.calls)
+	}
+}
+
+func TestExtractorCacheWriteFailure(t *testing.T) {
+	server := newFixtureServer(t)
+
+	// A closed cache fails every read and write
+	cache, err := OpenCache(filepath.Join(t.TempDir(), "cache.db"), time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cache.Close()
+
+	extractor := NewExtractor(NewHTTPFetcher())
+	extractor.Cache = cache
+
+	page, err := extractor.Fetch(context.Background(), server.URL+"/article")
+	if err != nil {
+		t.Fatalf("Fetch() error = %v, want the page despite the cache failure", err)
+	}
+	if !strings.Contains(string(page.Body), "Rivers of the Pacific Northwest") {
+		t.Errorf("Fetch() returned the wrong page")
+	}
+}
+
+// countingFetcher counts the fetches passed on to a Fetcher.
+type countingFetcher struct {
+	Fetcher
+	calls int
+}
+
+func (f *countingFetcher) Fetch(ctx context.Context, urlStr string) (*Page, error) {
+	f.calls++
+	return f.Fetcher.Fetch(ctx, urlStr)
+}
+
+// conditionalFetcher counts the fetches and revalidations passed on to an
+// HTTPFetcher.
+type conditionalFetcher struct {
+	*HTTPFetcher
+	fetches, revalidations int
+}
+
+func (f *conditionalFetcher) Fetch(ctx context.Context, urlStr string) (*Page, error) {
+	f.fetches++
+	return f.HTTPFetcher.Fetch(ctx, urlStr)
+}
+
+func (f *conditionalFetcher) FetchIfModified(ctx context.Context, urlStr, etag, lastModified string) (*Page, error) {
+	f.revalidations++
+	return f.HTTPFetcher.FetchIfModified(ctx, urlStr, etag, lastModified)
+}
+
+func TestExtractorCacheRevalidate(t *testing.T) {
+	server := newFixtureServer(t)
+	urlStr := server.URL + "/article"
+
+	cache, err := OpenCache(filepath.Join(t.TempDir(), "cache.db"), time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cache.Close()
+
+	page, err := NewHTTPFetcher().Fetch(context.Background(), urlStr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := cache.Put(urlStr, page); err != nil {
+		t.Fatal(err)
+	}
+	before, _ := cache.Get(urlStr)
+
+	// Every copy is expired until it has been revalidated
+	cache.TTL = time.Nanosecond
+	time.Sleep(time.Millisecond)
+
+	fetcher := &conditionalFetcher{HTTPFetcher: NewHTTPFetcher()}
+	extractor := NewExtractor(fetcher)
+	extractor.Cache = cache
+
+	got, err := extractor.Fetch(context.Background(), urlStr)
+	if err != nil {
+		t.Fatalf("Fetch() error = %v", err)
+	}
+	if string(got.Body) != string(page.Body) {
+		t.Errorf("Fetch() did not return the cached page")
+	}
+	if fetcher.revalidations != 1 || fetcher.fetches != 0 {
+		t.Errorf("revalidations = %d, fetches = %d, want 1 and 0", fetcher.revalidations, fetcher.fetches)
+	}
+
+	after, _ := cache.Get(urlStr)
+	if !after.FetchedAt.After(before.FetchedAt) {
+		t.Errorf("FetchedAt = %v, want it touched after %v", after.FetchedAt, before.FetchedAt)
+	}
+}
+
+func TestExtractorCacheOffline(t *testing.T) {
+	server := newFixtureServer(t)
+	urlStr := server.URL + "/article"
+
+	cache, err := OpenCache(filepath.Join(t.TempDir(), "cache.db"), time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cache.Close()
+
+	page, err := NewHTTPFetcher().Fetch(context.Background(), urlStr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := cache.Put(urlStr, page); err != nil {
+		t.Fatal(err)
+	}
+	cache.TTL = time.Nanosecond
+	time.Sleep(time.Millisecond)
+
+	offline := &failingFetcher{err: errors.New("offline")}
+	extractor := NewExtractor(offline)
+	extractor.Cache = cache
+
+	got, err := extractor.Fetch(context.Background(), urlStr)
+	if err != nil {
+		t.Fatalf("Fetch() error = %v, want the stale copy", err)
+	}
+	if string(got.Body) != string(page.Body) {
+		t.Errorf("Fetch() did not return the stale copy")
+	}
+	if offline.calls != 1 {
+		t.Errorf("fetcher called %d times, want 1", offline.calls)
+	}
+
+	// NoCache asks for a fresh copy, so there is nothing to fall back on
+	extractor.NoCache = true
+	if _, err := extractor.Fetch(context.Background(), urlStr); err == nil {
+		t.Errorf("Fetch() with NoCache succeeded offline")
+	}
+
+	// Without a cached copy the error is returned
+	extractor.NoCache = false
+	if _, err := extractor.Fetch(context.Background(), server.URL+"/journal"); err == nil {
+		t.Errorf("Fetch() of an uncached page succeeded offline")
+	}
+}
@@ -0,0 +1,180 @@
+package url
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"regexp"
+	"strings"
+
+	"github.com/knhn1004/bibtext-to-apa6/internal/bibtex"
+	"github.com/knhn1004/bibtext-to-apa6/internal/doi"
+)
+
+// Identifier kinds recognized by DetectIdentifier.
+const (
+	IdentifierDOI   = "doi"
+	IdentifierArXiv = "arxiv"
+	IdentifierPMID  = "pmid"
+	IdentifierISBN  = "isbn"
+)
+
+var (
+	arxivPattern = regexp.MustCompile(`(?i)^(?:arxiv:\s*|https?://(?:www\.|export\.)?arxiv\.org/(?:abs|pdf)/)?(\d{4}\.\d{4,5}(?:v\d+)?|[a-z\-]+(?:\.[a-z]{2})?/\d{7}(?:v\d+)?)(?:\.pdf)?/?$`)
+	pmidPattern  = regexp.MustCompile(`(?i)^(?:pmid:?\s*|https?://(?:www\.)?(?:pubmed\.ncbi\.nlm\.nih\.gov|ncbi\.nlm\.nih\.gov/pubmed)/)(\d{1,8})/?$`)
+	isbnPattern  = regexp.MustCompile(`(?i)^(?:isbn(?:-1[03])?:?\s*)?([0-9][0-9\- ]{8,15}[0-9x])$`)
+
+	// yearPattern finds the year in free-text dates such as "Spring 2005"
+	yearPattern = regexp.MustCompile(`\d{4}`)
+)
+
+// Identifier is a scholarly identifier pasted in place of a URL or BibTeX.
+type Identifier struct {
+	Kind  string
+	Value string
+}
+
+func (id Identifier) String() string {
+	return fmt.Sprintf("%s:%s", id.Kind, id.Value)
+}
+
+// DetectIdentifier recognizes DOIs, arXiv IDs ("arXiv:2301.01234"),
+// PubMed IDs ("PMID: 12345678") and ISBNs, including their common URL forms.
+func DetectIdentifier(input string) (Identifier, bool) {
+	input = strings.TrimSpace(input)
+
+	if d, err := doi.Parse(input); err == nil {
+		return Identifier{Kind: IdentifierDOI, Value: d}, true
+	}
+
+	if m := arxivPattern.FindStringSubmatch(input); m != nil {
+		return Identifier{Kind: IdentifierArXiv, Value: m[1]}, true
+	}
+
+	if m := pmidPattern.FindStringSubmatch(input); m != nil {
+		return Identifier{Kind: IdentifierPMID, Value: m[1]}, true
+	}
+
+	if m := isbnPattern.FindStringSubmatch(input); m != nil {
+		isbn := strings.ToUpper(strings.NewReplacer("-", "", " ", "").Replace(m[1]))
+		if validISBN(isbn) {
+			return Identifier{Kind: IdentifierISBN, Value: isbn}, true
+		}
+	}
+
+	return Identifier{}, false
+}
+
+// validISBN checks the length and check digit of an ISBN-10 or ISBN-13.
+func validISBN(isbn string) bool {
+	switch len(isbn) {
+	case 10:
+		sum := 0
+		for i, c := range isbn {
+			var v int
+			switch {
+			case c >= '0' && c <= '9':
+				v = int(c - '0')
+			case c == 'X' && i == 9:
+				v = 10
+			default:
+				return false
+			}
+			sum += v * (10 - i)
+		}
+		return sum%11 == 0
+	case 13:
+		sum := 0
+		for i, c := range isbn {
+			if c < '0' || c > '9' {
+				return false
+			}
+			weight := 1
+			if i%2 == 1 {
+				weight = 3
+			}
+			sum += int(c-'0') * weight
+		}
+		return sum%10 == 0
+	}
+	return false
+}
+
+// Resolver turns an identifier into a BibTeX entry.
+type Resolver interface {
+	Resolve(ctx context.Context, id string) (*bibtex.Entry, error)
+}
+
+// Resolvers holds one resolver per identifier kind.
+type Resolvers struct {
+	DOI    Resolver
+	ArXiv  Resolver
+	PubMed Resolver
+	ISBN   Resolver
+}
+
+// DefaultResolvers returns resolvers for the public doi.org, arXiv, NCBI and
+// Open Library endpoints.
+func DefaultResolvers() *Resolvers {
+	return &Resolvers{
+		DOI:    NewDOIResolver(),
+		ArXiv:  NewArXivResolver(),
+		PubMed: NewPubMedResolver(),
+		ISBN:   NewOpenLibraryResolver(),
+	}
+}
+
+// Resolve looks up an identifier with the resolver for its kind.
+func (r *Resolvers) Resolve(ctx context.Context, id Identifier) (*bibtex.Entry, error) {
+	var resolver Resolver
+	switch id.Kind {
+	case IdentifierDOI:
+		resolver = r.DOI
+	case IdentifierArXiv:
+		resolver = r.ArXiv
+	case IdentifierPMID:
+		resolver = r.PubMed
+	case IdentifierISBN:
+		resolver = r.ISBN
+	}
+
+	if resolver == nil {
+		return nil, fmt.Errorf("no resolver configured for %s identifiers", id.Kind)
+	}
+
+	return resolver.Resolve(ctx, id.Value)
+}
+
+// getBody performs a GET request and returns the response body, treating
+// any status other than 200 as an error.
+func getBody(ctx context.Context, client *http.Client, urlStr, accept string) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", urlStr, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	if accept != "" {
+		req.Header.Set("Accept", accept)
+	}
+
+	if client == nil {
+		client = http.DefaultClient
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch %s: %w", urlStr, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("HTTP error: %s", resp.Status)
+	}
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxBodySize))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response: %w", err)
+	}
+
+	return body, nil
+}
@@ -0,0 +1,118 @@
+package url
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/knhn1004/bibtext-to-apa6/internal/bibtex"
+)
+
+// DefaultOpenLibraryBaseURL is the Open Library API host.
+const DefaultOpenLibraryBaseURL = "https://openlibrary.org"
+
+// OpenLibraryResolver looks up books by ISBN through the Open Library Books
+// API.
+type OpenLibraryResolver struct {
+	BaseURL string
+	Client  *http.Client
+}
+
+// NewOpenLibraryResolver returns a resolver that queries openlibrary.org.
+func NewOpenLibraryResolver() *OpenLibraryResolver {
+	return &OpenLibraryResolver{
+		BaseURL: DefaultOpenLibraryBaseURL,
+		Client:  &http.Client{Timeout: 15 * time.Second},
+	}
+}
+
+type openLibraryName struct {
+	Name string `json:"name"`
+}
+
+type openLibraryBook struct {
+	Title         string            `json:"title"`
+	Subtitle      string            `json:"subtitle"`
+	Authors       []openLibraryName `json:"authors"`
+	Publishers    []openLibraryName `json:"publishers"`
+	PublishPlaces []openLibraryName `json:"publish_places"`
+	PublishDate   string            `json:"publish_date"`
+	URL           string            `json:"url"`
+}
+
+// Resolve fetches the book record for an ISBN-10 or ISBN-13.
+func (r *OpenLibraryResolver) Resolve(ctx context.Context, isbn string) (*bibtex.Entry, error) {
+	baseURL := strings.TrimRight(r.BaseURL, "/")
+	if baseURL == "" {
+		baseURL = DefaultOpenLibraryBaseURL
+	}
+
+	bibkey := "ISBN:" + isbn
+	query := url.Values{
+		"bibkeys": {bibkey},
+		"format":  {"json"},
+		"jscmd":   {"data"},
+	}
+
+	body, err := getBody(ctx, r.Client, baseURL+"/api/books?"+query.Encode(), "application/json")
+	if err != nil {
+		return nil, err
+	}
+
+	var books map[string]openLibraryBook
+	if err := json.Unmarshal(body, &books); err != nil {
+		return nil, fmt.Errorf("failed to parse Open Library response: %w", err)
+	}
+
+	book, ok := books[bibkey]
+	if !ok {
+		return nil, fmt.Errorf("ISBN not found: %s", isbn)
+	}
+
+	return book.toEntry(isbn), nil
+}
+
+func (b *openLibraryBook) toEntry(isbn string) *bibtex.Entry {
+	entry := &bibtex.Entry{
+		Type:   "book",
+		Fields: make(map[string]string),
+	}
+
+	set := func(field, value string) {
+		if value = strings.TrimSpace(value); value != "" {
+			entry.Fields[field] = value
+		}
+	}
+
+	authors := []string{}
+	for _, a := range b.Authors {
+		if name := strings.TrimSpace(a.Name); name != "" {
+			authors = append(authors, name)
+		}
+	}
+	set("author", strings.Join(authors, " and "))
+
+	title := b.Title
+	if b.Subtitle != "" {
+		title = fmt.Sprintf("%s: %s", title, b.Subtitle)
+	}
+	set("title", title)
+
+	// publish_date is free text such as "March 2005" or "2005"
+	set("year", yearPattern.FindString(b.PublishDate))
+	if len(b.Publishers) > 0 {
+		set("publisher", b.Publishers[0].Name)
+	}
+	if len(b.PublishPlaces) > 0 {
+		set("address", b.PublishPlaces[0].Name)
+	}
+	set("isbn", isbn)
+
+	entry.Key = entry.GenerateKey("isbn")
+
+	return entry
+}
@@ -0,0 +1,226 @@
+package url
+
+import (
+	"encoding/json"
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+	"github.com/knhn1004/bibtext-to-apa6/internal/bibtex"
+)
+
+// jsonLD holds the fields read from schema.org JSON-LD blocks.
+type jsonLD struct {
+	Type          string
+	Title         string
+	Authors       []bibtex.Name
+	DatePublished string
+	DateModified  string
+	Publisher     string
+}
+
+// schemaTypes maps schema.org types to metadata types, in order of
+// preference when a page describes several things.
+var schemaTypes = []struct {
+	schema string
+	kind   string
+}{
+	{"ScholarlyArticle", TypeJournalArticle},
+	{"MedicalScholarlyArticle", TypeJournalArticle},
+	{"NewsArticle", TypeNewsArticle},
+	{"ReportageNewsArticle", TypeNewsArticle},
+	{"AnalysisNewsArticle", TypeNewsArticle},
+	{"OpinionNewsArticle", TypeNewsArticle},
+	{"BlogPosting", TypeBlogPost},
+	{"SocialMediaPosting", TypeSocialPost},
+	{"DiscussionForumPosting", TypeSocialPost},
+	{"VideoObject", TypeVideo},
+	{"Article", TypeArticle},
+	{"TechArticle", TypeArticle},
+	{"Report", TypeArticle},
+	{"WebPage", TypeWebPage},
+}
+
+// extractJSONLD reads the <script type="application/ld+json"> blocks of a
+// page and returns the metadata of its primary creative work, or nil if the
+// page has none.
+func extractJSONLD(doc *goquery.Document) *jsonLD {
+	nodes := []map[string]interface{}{}
+
+	doc.Find(`script[type="application/ld+json"]`).Each(func(i int, s *goquery.Selection) {
+		var data interface{}
+		if err := json.Unmarshal([]byte(strings.TrimSpace(s.Text())), &data); err != nil {
+			return
+		}
+		nodes = append(nodes, flattenJSONLD(data)...)
+	})
+
+	if len(nodes) == 0 {
+		return nil
+	}
+
+	// Index nodes by @id so that references such as {"@id": "#author"} in
+	// an @graph can be resolved
+	ids := map[string]map[string]interface{}{}
+	for _, node := range nodes {
+		if id, ok := node["@id"].(string); ok {
+			ids[id] = node
+		}
+	}
+
+	for _, t := range schemaTypes {
+		for _, node := range nodes {
+			if !hasSchemaType(node, t.schema) {
+				continue
+			}
+
+			ld := &jsonLD{
+				Type:          t.kind,
+				Title:         firstString(node, "headline", "name"),
+				Authors:       jsonLDAuthors(node["author"], ids),
+				DatePublished: firstString(node, "datePublished", "dateCreated", "uploadDate"),
+				DateModified:  firstString(node, "dateModified"),
+			}
+			if publishers := jsonLDNames(node["publisher"], ids); len(publishers) > 0 {
+				ld.Publisher = publishers[0]
+			}
+			if len(ld.Authors) == 0 {
+				ld.Authors = jsonLDAuthors(node["creator"], ids)
+			}
+
+			return ld
+		}
+	}
+
+	return nil
+}
+
+// flattenJSONLD turns a JSON-LD document, which may be an object, an array of
+// objects or an object with an @graph, into a flat list of nodes.
+func flattenJSONLD(data interface{}) []map[string]interface{} {
+	nodes := []map[string]interface{}{}
+
+	switch v := data.(type) {
+	case []interface{}:
+		for _, item := range v {
+			nodes = append(nodes, flattenJSONLD(item)...)
+		}
+	case map[string]interface{}:
+		if graph, ok := v["@graph"]; ok {
+			nodes = append(nodes, flattenJSONLD(graph)...)
+		}
+		if _, ok := v["@type"]; ok {
+			nodes = append(nodes, v)
+		}
+	}
+
+	return nodes
+}
+
+func hasSchemaType(node map[string]interface{}, schemaType string) bool {
+	switch t := node["@type"].(type) {
+	case string:
+		return strings.TrimPrefix(t, "schema:") == schemaType
+	case []interface{}:
+		for _, item := range t {
+			if s, ok := item.(string); ok && strings.TrimPrefix(s, "schema:") == schemaType {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// jsonLDAuthors reads Person and Organization nodes as names, preferring
+// the structured givenName/familyName of a Person over its display name.
+func jsonLDAuthors(value interface{}, ids map[string]map[string]interface{}) []bibtex.Name {
+	names := []bibtex.Name{}
+
+	switch v := value.(type) {
+	case string:
+		if name := strings.TrimSpace(v); name != "" && !isURLOrEmail(name) {
+			names = append(names, parseAuthorName(name))
+		}
+	case []interface{}:
+		for _, item := range v {
+			names = append(names, jsonLDAuthors(item, ids)...)
+		}
+	case map[string]interface{}:
+		if id, ok := v["@id"].(string); ok && len(v) == 1 {
+			if ref, ok := ids[id]; ok {
+				v = ref
+			}
+		}
+
+		given := firstString(v, "givenName")
+		family := firstString(v, "familyName")
+		name := firstString(v, "name")
+
+		switch {
+		case hasSchemaType(v, "Organization") || hasSchemaType(v, "NewsMediaOrganization"):
+			if name != "" {
+				names = append(names, bibtex.Name{Family: name, Corporate: true})
+			}
+		case family != "":
+			names = append(names, bibtex.Name{Family: family, Given: given})
+		case name != "":
+			names = append(names, parseAuthorName(name))
+		}
+	}
+
+	return names
+}
+
+// jsonLDNames reads person or organization names from a value that may be a
+// string, a Person/Organization object, an @id reference or an array of any
+// of these.
+func jsonLDNames(value interface{}, ids map[string]map[string]interface{}) []string {
+	names := []string{}
+
+	switch v := value.(type) {
+	case string:
+		if name := strings.TrimSpace(v); name != "" {
+			names = append(names, name)
+		}
+	case []interface{}:
+		for _, item := range v {
+			names = append(names, jsonLDNames(item, ids)...)
+		}
+	case map[string]interface{}:
+		if id, ok := v["@id"].(string); ok && len(v) == 1 {
+			if ref, ok := ids[id]; ok {
+				v = ref
+			}
+		}
+
+		if name := firstString(v, "name"); name != "" {
+			names = append(names, name)
+		} else {
+			given := firstString(v, "givenName")
+			family := firstString(v, "familyName")
+			if name := strings.TrimSpace(given + " " + family); name != "" {
+				names = append(names, name)
+			}
+		}
+	}
+
+	return names
+}
+
+// firstString returns the first non-empty string value among the given keys.
+func firstString(node map[string]interface{}, keys ...string) string {
+	for _, key := range keys {
+		switch v := node[key].(type) {
+		case string:
+			if s := strings.TrimSpace(v); s != "" {
+				return s
+			}
+		case []interface{}:
+			for _, item := range v {
+				if s, ok := item.(string); ok && strings.TrimSpace(s) != "" {
+					return strings.TrimSpace(s)
+				}
+			}
+		}
+	}
+	return ""
+}
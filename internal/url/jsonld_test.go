@@ -0,0 +1,121 @@
+package url
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/PuerkitoBio/goquery"
+	"github.com/knhn1004/bibtext-to-apa6/internal/bibtex"
+)
+
+// loadDocument parses an HTML fixture from testdata.
+func loadDocument(t *testing.T, name string) *goquery.Document {
+	t.Helper()
+
+	f, err := os.Open(filepath.Join("testdata", name))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	doc, err := goquery.NewDocumentFromReader(f)
+	if err != nil {
+		t.Fatalf("failed to parse %s: %v", name, err)
+	}
+	return doc
+}
+
+func TestExtractJSONLD(t *testing.T) {
+	tests := []struct {
+		fixture string
+		want    *jsonLD
+	}{
+		{
+			fixture: "jsonld/graph.html",
+			want: &jsonLD{
+				Type:          TypeNewsArticle,
+				Title:         "Graph article",
+				Authors:       []bibtex.Name{{Family: "Doe", Given: "Jane"}},
+				DatePublished: "2021-03-04T10:00:00Z",
+				DateModified:  "2021-03-05T08:00:00Z",
+				Publisher:     "Example Media",
+			},
+		},
+		{
+			fixture: "jsonld/array.html",
+			want: &jsonLD{
+				Type:          TypeBlogPost,
+				Title:         "Notes on arrays",
+				Authors:       []bibtex.Name{{Family: "Lee", Given: "Ann"}, {Family: "Ray", Given: "Carl"}},
+				DatePublished: "2019-07",
+				Publisher:     "Array Blog",
+			},
+		},
+		{
+			fixture: "jsonld/nested.html",
+			want: &jsonLD{
+				Type:  TypeJournalArticle,
+				Title: "Nested authors in practice",
+				Authors: []bibtex.Name{
+					{Family: "García López", Given: "María"},
+					{Family: "World Health Organization", Corporate: true},
+				},
+				DatePublished: "2018-01-15",
+				Publisher:     "Science Press",
+			},
+		},
+		{
+			fixture: "jsonld/invalid.html",
+			want: &jsonLD{
+				Type:          TypeVideo,
+				Title:         "Recovered video",
+				Authors:       []bibtex.Name{{Family: "Scully", Given: "Dana"}},
+				DatePublished: "2020-02-02",
+			},
+		},
+		{
+			fixture: "jsonld/none.html",
+			want:    nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.fixture, func(t *testing.T) {
+			got := extractJSONLD(loadDocument(t, tt.fixture))
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("extractJSONLD() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+// TestJSONLDType checks that structured data only replaces the og:type of a
+// page with a type that says at least as much.
+func TestJSONLDType(t *testing.T) {
+	tests := []struct {
+		fixture    string
+		wantType   string
+		wantSource Source
+	}{
+		// A generic WebPage does not demote an og:type article
+		{"jsonld/webpage-article.html", TypeArticle, SourceOpenGraph},
+		{"jsonld/article-video.html", TypeVideo, SourceOpenGraph},
+		{"jsonld/news-article.html", TypeNewsArticle, SourceJSONLD},
+		{"jsonld/webpage.html", TypeWebPage, SourceJSONLD},
+		{"jsonld/nested.html", TypeJournalArticle, SourceJSONLD},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.fixture, func(t *testing.T) {
+			m := parseDocument(loadDocument(t, tt.fixture), "https://example.com/page")
+			if m.Type != tt.wantType {
+				t.Errorf("Type = %q, want %q", m.Type, tt.wantType)
+			}
+			if got := m.Provenance[FieldType].Source; got != tt.wantSource {
+				t.Errorf("type source = %q, want %q", got, tt.wantSource)
+			}
+		})
+	}
+}
@@ -0,0 +1,254 @@
+package url
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/net/publicsuffix"
+)
+
+// maxRedirects is how many redirects a link check follows before giving up.
+const maxRedirects = 10
+
+// LinkStatus classifies the outcome of a link check.
+type LinkStatus string
+
+const (
+	LinkOK         LinkStatus = "ok"         // answered directly, or after a trivial redirect
+	LinkRedirected LinkStatus = "redirected" // moved to another URL on the same site
+	LinkMoved      LinkStatus = "moved"      // moved to another domain
+	LinkDead       LinkStatus = "dead"       // 404 Not Found, 410 Gone, or the host no longer exists
+	LinkError      LinkStatus = "error"      // any other failure, possibly temporary
+)
+
+// Redirect is one hop of a redirect chain.
+type Redirect struct {
+	URL        string `json:"url"`
+	StatusCode int    `json:"status_code"`
+}
+
+// LinkResult is the outcome of checking one URL.
+type LinkResult struct {
+	URL        string     `json:"url"`
+	Status     LinkStatus `json:"status"`
+	StatusCode int        `json:"status_code,omitempty"`
+	FinalURL   string     `json:"final_url,omitempty"`
+	Redirects  []Redirect `json:"redirects,omitempty"`
+	Error      string     `json:"error,omitempty"`
+	CheckedAt  time.Time  `json:"checked_at"`
+}
+
+// LinkChecker checks whether URLs still work, following redirects one hop at
+// a time so the whole chain can be reported. Many URLs are checked
+// concurrently, with a politeness delay between requests to the same host.
+type LinkChecker struct {
+	Client    *http.Client
+	UserAgent string
+	Workers   int
+	HostDelay time.Duration
+
+	// Progress, if set, is called after each URL completes.
+	Progress func(done, total int, result LinkResult)
+}
+
+// NewLinkChecker returns a LinkChecker with 8 workers, a 15 second timeout
+// and one request per host every half second.
+func NewLinkChecker() *LinkChecker {
+	return &LinkChecker{
+		Client:    &http.Client{Timeout: 15 * time.Second},
+		UserAgent: defaultUserAgent,
+		Workers:   8,
+		HostDelay: 500 * time.Millisecond,
+	}
+}
+
+// CheckAll checks all URLs and returns their results in input order.
+func (c *LinkChecker) CheckAll(ctx context.Context, urls []string) []LinkResult {
+	results := make([]LinkResult, len(urls))
+	limiter := newHostLimiter(c.HostDelay)
+
+	workers := c.Workers
+	if workers < 1 {
+		workers = 1
+	}
+
+	jobs := make(chan int)
+	var (
+		wg       sync.WaitGroup
+		mu       sync.Mutex
+		finished int
+	)
+
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				results[i] = c.check(ctx, limiter, urls[i])
+
+				if c.Progress != nil {
+					mu.Lock()
+					finished++
+					c.Progress(finished, len(urls), results[i])
+					mu.Unlock()
+				}
+			}
+		}()
+	}
+
+	for i := range urls {
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+
+	return results
+}
+
+// Check checks a single URL.
+func (c *LinkChecker) Check(ctx context.Context, urlStr string) LinkResult {
+	return c.check(ctx, nil, urlStr)
+}
+
+func (c *LinkChecker) check(ctx context.Context, limiter *hostLimiter, urlStr string) LinkResult {
+	result := LinkResult{URL: urlStr, CheckedAt: time.Now()}
+
+	start, err := url.Parse(urlStr)
+	if err != nil || start.Hostname() == "" {
+		result.Status = LinkError
+		result.Error = fmt.Sprintf("invalid URL: %s", urlStr)
+		return result
+	}
+
+	current := start
+	for hop := 0; ; hop++ {
+		if limiter != nil {
+			if err := limiter.Wait(ctx, current.Hostname()); err != nil {
+				result.Status = LinkError
+				result.Error = err.Error()
+				return result
+			}
+		}
+
+		resp, err := c.request(ctx, current.String())
+		if err != nil {
+			result.FinalURL = current.String()
+			var dnsErr *net.DNSError
+			if errors.As(err, &dnsErr) && dnsErr.IsNotFound {
+				result.Status = LinkDead
+			} else {
+				result.Status = LinkError
+			}
+			result.Error = err.Error()
+			return result
+		}
+
+		location := resp.Header.Get("Location")
+		if resp.StatusCode < 300 || resp.StatusCode >= 400 || location == "" {
+			result.StatusCode = resp.StatusCode
+			result.FinalURL = current.String()
+			break
+		}
+
+		next, err := current.Parse(location)
+		if err != nil || hop >= maxRedirects {
+			result.Status = LinkError
+			result.StatusCode = resp.StatusCode
+			result.FinalURL = current.String()
+			result.Error = "too many or invalid redirects"
+			return result
+		}
+
+		result.Redirects = append(result.Redirects, Redirect{URL: next.String(), StatusCode: resp.StatusCode})
+		current = next
+	}
+
+	switch {
+	case result.StatusCode == http.StatusNotFound || result.StatusCode == http.StatusGone:
+		result.Status = LinkDead
+	case result.StatusCode >= 400:
+		result.Status = LinkError
+		result.Error = fmt.Sprintf("HTTP error: %d %s", result.StatusCode, http.StatusText(result.StatusCode))
+	case len(result.Redirects) == 0 || trivialRedirect(start, current):
+		result.Status = LinkOK
+	case siteDomain(start.Hostname()) != siteDomain(current.Hostname()):
+		result.Status = LinkMoved
+	default:
+		result.Status = LinkRedirected
+	}
+
+	return result
+}
+
+// request sends a HEAD request without following redirects, falling back to
+// GET for servers that do not answer HEAD properly.
+func (c *LinkChecker) request(ctx context.Context, urlStr string) (*http.Response, error) {
+	client := c.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	noRedirects := *client
+	noRedirects.CheckRedirect = func(*http.Request, []*http.Request) error {
+		return http.ErrUseLastResponse
+	}
+
+	userAgent := c.UserAgent
+	if userAgent == "" {
+		userAgent = defaultUserAgent
+	}
+
+	var resp *http.Response
+	for _, method := range []string{"HEAD", "GET"} {
+		req, err := http.NewRequestWithContext(ctx, method, urlStr, nil)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create request: %w", err)
+		}
+		req.Header.Set("User-Agent", userAgent)
+
+		resp, err = noRedirects.Do(req)
+		if err != nil {
+			return nil, err
+		}
+		resp.Body.Close()
+
+		// Some servers reject HEAD requests outright
+		if method == "HEAD" && (resp.StatusCode == http.StatusMethodNotAllowed || resp.StatusCode == http.StatusForbidden || resp.StatusCode == http.StatusNotImplemented) {
+			continue
+		}
+		break
+	}
+
+	return resp, nil
+}
+
+// trivialRedirect reports whether a redirect only upgraded to HTTPS, added or
+// removed "www." or a trailing slash.
+func trivialRedirect(from, to *url.URL) bool {
+	return strings.TrimPrefix(from.Hostname(), "www.") == strings.TrimPrefix(to.Hostname(), "www.") &&
+		strings.TrimSuffix(from.EscapedPath(), "/") == strings.TrimSuffix(to.EscapedPath(), "/") &&
+		from.RawQuery == to.RawQuery
+}
+
+// siteDomain reduces a host name to the domain registered under its public
+// suffix, such as bbc.co.uk for news.bbc.co.uk or bbc.de for www.bbc.de, so
+// that moves between subdomains are not reported as domain changes.
+func siteDomain(host string) string {
+	host = strings.ToLower(strings.TrimSuffix(host, "."))
+	if net.ParseIP(host) != nil {
+		return host
+	}
+
+	domain, err := publicsuffix.EffectiveTLDPlusOne(host)
+	if err != nil {
+		// The host is itself a public suffix, or a single label
+		return host
+	}
+	return domain
+}
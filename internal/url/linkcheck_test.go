@@ -0,0 +1,161 @@
+package url
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// newLinkServer returns a LinkChecker whose requests for any host name are
+// answered by one test server, so that moves between domains can be
+// checked. Hosts under .invalid do not resolve.
+func newLinkServer(t *testing.T) *LinkChecker {
+	t.Helper()
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/ok", func(w http.ResponseWriter, r *http.Request) {})
+	mux.HandleFunc("/missing", http.NotFound)
+	mux.HandleFunc("/gone", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusGone)
+	})
+	mux.HandleFunc("/broken", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	})
+	mux.HandleFunc("/no-head", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == "HEAD" {
+			w.WriteHeader(http.StatusMethodNotAllowed)
+		}
+	})
+	mux.HandleFunc("/dir", func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, "https://"+r.Host+"/dir/", http.StatusMovedPermanently)
+	})
+	mux.HandleFunc("/chain", func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, "/step", http.StatusFound)
+	})
+	mux.HandleFunc("/step", func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, "http://news.bbc.de/ok", http.StatusMovedPermanently)
+	})
+	mux.HandleFunc("/sold", func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, "http://example.org/ok", http.StatusMovedPermanently)
+	})
+	mux.HandleFunc("/loop", func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, "/loop", http.StatusFound)
+	})
+
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+
+	dialer := &net.Dialer{}
+	transport := &http.Transport{
+		DialContext: func(ctx context.Context, network, addr string) (net.Conn, error) {
+			host, _, _ := net.SplitHostPort(addr)
+			if strings.HasSuffix(host, ".invalid") {
+				return nil, &net.DNSError{Err: "no such host", Name: host, IsNotFound: true}
+			}
+			return dialer.DialContext(ctx, network, server.Listener.Addr().String())
+		},
+	}
+	t.Cleanup(transport.CloseIdleConnections)
+
+	checker := NewLinkChecker()
+	checker.Client = &http.Client{Transport: transport}
+	return checker
+}
+
+func TestLinkCheckerCheck(t *testing.T) {
+	checker := newLinkServer(t)
+
+	tests := []struct {
+		url           string
+		wantStatus    LinkStatus
+		wantCode      int
+		wantFinal     string
+		wantRedirects int
+	}{
+		{"http://www.bbc.de/ok", LinkOK, http.StatusOK, "http://www.bbc.de/ok", 0},
+		{"http://www.bbc.de/missing", LinkDead, http.StatusNotFound, "http://www.bbc.de/missing", 0},
+		{"http://www.bbc.de/gone", LinkDead, http.StatusGone, "http://www.bbc.de/gone", 0},
+		{"http://www.bbc.de/broken", LinkError, http.StatusInternalServerError, "http://www.bbc.de/broken", 0},
+
+		// HEAD is refused, so the link is checked with GET
+		{"http://www.bbc.de/no-head", LinkOK, http.StatusOK, "http://www.bbc.de/no-head", 0},
+
+		// Upgrading to HTTPS is trivial, but this server does not speak TLS
+		{"http://www.bbc.de/dir", LinkError, 0, "https://www.bbc.de/dir/", 1},
+
+		// A chain ending on another subdomain of the same site
+		{"http://www.bbc.de/chain", LinkRedirected, http.StatusOK, "http://news.bbc.de/ok", 2},
+		// A move to another domain, and a redirect loop
+		{"http://www.bbc.co.uk/sold", LinkMoved, http.StatusOK, "http://example.org/ok", 1},
+		{"http://www.bbc.de/loop", LinkError, http.StatusFound, "http://www.bbc.de/loop", maxRedirects},
+
+		{"http://expired.invalid/ok", LinkDead, 0, "http://expired.invalid/ok", 0},
+		{"not a url", LinkError, 0, "", 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.url, func(t *testing.T) {
+			result := checker.Check(context.Background(), tt.url)
+
+			if result.Status != tt.wantStatus {
+				t.Errorf("Status = %q, want %q (error %q)", result.Status, tt.wantStatus, result.Error)
+			}
+			if result.StatusCode != tt.wantCode {
+				t.Errorf("StatusCode = %d, want %d", result.StatusCode, tt.wantCode)
+			}
+			if result.FinalURL != tt.wantFinal {
+				t.Errorf("FinalURL = %q, want %q", result.FinalURL, tt.wantFinal)
+			}
+			if len(result.Redirects) != tt.wantRedirects {
+				t.Errorf("Redirects = %v, want %d hops", result.Redirects, tt.wantRedirects)
+			}
+		})
+	}
+}
+
+func TestLinkCheckerRedirectChain(t *testing.T) {
+	result := newLinkServer(t).Check(context.Background(), "http://www.bbc.de/chain")
+
+	want := []Redirect{
+		{URL: "http://www.bbc.de/step", StatusCode: http.StatusFound},
+		{URL: "http://news.bbc.de/ok", StatusCode: http.StatusMovedPermanently},
+	}
+	if len(result.Redirects) != len(want) {
+		t.Fatalf("Redirects = %v, want %v", result.Redirects, want)
+	}
+	for i := range want {
+		if result.Redirects[i] != want[i] {
+			t.Errorf("Redirects[%d] = %v, want %v", i, result.Redirects[i], want[i])
+		}
+	}
+}
+
+func TestSiteDomain(t *testing.T) {
+	tests := []struct {
+		host string
+		want string
+	}{
+		{"example.com", "example.com"},
+		{"www.example.com", "example.com"},
+		{"WWW.Example.COM.", "example.com"},
+		{"www.bbc.de", "bbc.de"},
+		{"news.bbc.de", "bbc.de"},
+		{"www.bbc.co.uk", "bbc.co.uk"},
+		{"news.bbc.co.uk", "bbc.co.uk"},
+		{"www.cam.ac.uk", "cam.ac.uk"},
+		{"a.b.example.com.au", "example.com.au"},
+		{"co.uk", "co.uk"},
+		{"localhost", "localhost"},
+		{"127.0.0.1", "127.0.0.1"},
+		{"::1", "::1"},
+	}
+
+	for _, tt := range tests {
+		if got := siteDomain(tt.host); got != tt.want {
+			t.Errorf("siteDomain(%q) = %q, want %q", tt.host, got, tt.want)
+		}
+	}
+}
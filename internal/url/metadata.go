@@ -1,69 +1,97 @@
 package url
 
 import (
+	"bytes"
+	"context"
 	"fmt"
-	"net/http"
+	"io"
 	"net/url"
 	"strings"
 	"time"
+	"unicode/utf8"
 
 	"github.com/PuerkitoBio/goquery"
-	"github.com/playwright-community/playwright-go"
+	"github.com/knhn1004/bibtext-to-apa6/internal/apa"
+	"github.com/knhn1004/bibtext-to-apa6/internal/bibtex"
+	"github.com/knhn1004/bibtext-to-apa6/internal/doi"
+	"golang.org/x/net/html/charset"
+)
+
+// Metadata types, describing what kind of source a page is.
+const (
+	TypeWebPage        = "webpage"
+	TypeArticle        = "article"
+	TypeJournalArticle = "journal-article"
+	TypeNewsArticle    = "news"
+	TypeBlogPost       = "blog"
+	TypeSocialPost     = "social"
+	TypeVideo          = "video"
+	TypeSoftware       = "software"
+	TypeWiki           = "wiki"
 )
 
 type Metadata struct {
+	Type       string
 	Title      string
-	Author     string
-	Year       string
+	Authors    []bibtex.Name
+	Date       Date
 	Publisher  string
+	Journal    string
+	Volume     string
+	Issue      string
+	FirstPage  string
+	LastPage   string
 	URL        string
+	DOI        string
 	AccessDate time.Time
+
+	// ScreenName is the handle of the account a social media post was
+	// made from, such as "BarackObama".
+	ScreenName string
+
+	// Provenance records where each field came from, keyed by the Field
+	// constants.
+	Provenance map[string]Provenance
 }
 
 // ExtractMetadata tries HTTP first, then falls back to Playwright if that fails
 func ExtractMetadata(urlStr string) (*Metadata, error) {
-	_, err := url.Parse(urlStr)
-	if err != nil {
-		return nil, fmt.Errorf("invalid URL: %w", err)
-	}
+	extractor := DefaultExtractor()
+	defer extractor.Close()
 
-	// Try HTTP method first
-	metadata, err := extractMetadataHTTP(urlStr)
-	if err == nil {
-		return metadata, nil
-	}
-
-	// Fall back to Playwright for sites that block simple HTTP requests
-	return extractMetadataPlaywright(urlStr)
+	return extractor.Extract(context.Background(), urlStr)
 }
 
-// extractMetadataHTTP uses a simple HTTP client with browser-like headers
-func extractMetadataHTTP(urlStr string) (*Metadata, error) {
-	client := &http.Client{
-		Timeout: 10 * time.Second,
-	}
-
-	req, err := http.NewRequest("GET", urlStr, nil)
-	if err != nil {
-		return nil, fmt.Errorf("failed to create request: %w", err)
-	}
+// ParseHTML extracts metadata from an already fetched HTML document. The
+// character encoding is taken from a byte order mark or <meta charset>;
+// without either, valid UTF-8 is kept as is and anything else is decoded
+// as windows-1252.
+func ParseHTML(r io.Reader, urlStr string) (*Metadata, error) {
+	return parseHTML(r, "", urlStr)
+}
 
-	// Set headers to mimic a browser request
-	req.Header.Set("User-Agent", "Mozilla/5.0 (Macintosh; Intel Mac OS X 10_15_7) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/120.0.0.0 Safari/537.36")
-	req.Header.Set("Accept", "text/html,application/xhtml+xml,application/xml;q=0.9,image/avif,image/webp,image/apng,*/*;q=0.8")
-	req.Header.Set("Accept-Language", "en-US,en;q=0.9")
+// ParsePage extracts metadata from a fetched page, decoding it with the
+// charset of its Content-Type header if it has one. urlStr is used when the
+// page does not name a canonical URL.
+func ParsePage(page *Page, urlStr string) (*Metadata, error) {
+	return parseHTML(bytes.NewReader(page.Body), page.Header.Get("Content-Type"), urlStr)
+}
 
-	resp, err := client.Do(req)
+func parseHTML(r io.Reader, contentType, urlStr string) (*Metadata, error) {
+	body, err := io.ReadAll(r)
 	if err != nil {
-		return nil, fmt.Errorf("failed to fetch URL: %w", err)
+		return nil, fmt.Errorf("failed to read HTML: %w", err)
 	}
-	defer resp.Body.Close()
 
-	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("HTTP error: %s", resp.Status)
+	// Without this, pages in legacy encodings get mojibake titles. The
+	// sniffer only looks at the first 1024 bytes and guesses windows-1252
+	// when it finds no declaration, which would garble undeclared UTF-8.
+	var decoded io.Reader = bytes.NewReader(body)
+	if e, _, certain := charset.DetermineEncoding(body, contentType); certain || !utf8.Valid(body) {
+		decoded = e.NewDecoder().Reader(decoded)
 	}
 
-	doc, err := goquery.NewDocumentFromReader(resp.Body)
+	doc, err := goquery.NewDocumentFromReader(decoded)
 	if err != nil {
 		return nil, fmt.Errorf("failed to parse HTML: %w", err)
 	}
@@ -71,73 +99,113 @@ func extractMetadataHTTP(urlStr string) (*Metadata, error) {
 	return parseDocument(doc, urlStr), nil
 }
 
-// extractMetadataPlaywright uses a headless browser to fetch the page
-func extractMetadataPlaywright(urlStr string) (*Metadata, error) {
-	pw, err := playwright.Run()
-	if err != nil {
-		return nil, fmt.Errorf("failed to start playwright: %w", err)
+// parseDocument extracts metadata from a goquery document
+func parseDocument(doc *goquery.Document, urlStr string) *Metadata {
+	metadata := &Metadata{
+		URL:        canonicalURL(doc, urlStr),
+		AccessDate: time.Now(),
 	}
-	defer pw.Stop()
 
-	browser, err := pw.Chromium.Launch(playwright.BrowserTypeLaunchOptions{
-		Headless: playwright.Bool(true),
-	})
-	if err != nil {
-		return nil, fmt.Errorf("failed to launch browser: %w", err)
-	}
-	defer browser.Close()
+	var source Source
 
-	context, err := browser.NewContext(playwright.BrowserNewContextOptions{
-		UserAgent: playwright.String("Mozilla/5.0 (Macintosh; Intel Mac OS X 10_15_7) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/120.0.0.0 Safari/537.36"),
-	})
-	if err != nil {
-		return nil, fmt.Errorf("failed to create browser context: %w", err)
-	}
-	defer context.Close()
+	metadata.Type, source = extractType(doc)
+	metadata.setSource(FieldType, source)
 
-	page, err := context.NewPage()
-	if err != nil {
-		return nil, fmt.Errorf("failed to create page: %w", err)
+	metadata.Title, source = extractTitle(doc)
+	if source == SourceHTML {
+		metadata.setConfidence(FieldTitle, source, titleConfidence(metadata.Title))
+	} else {
+		metadata.setSource(FieldTitle, source)
 	}
 
-	_, err = page.Goto(urlStr, playwright.PageGotoOptions{
-		WaitUntil: playwright.WaitUntilStateNetworkidle,
-		Timeout:   playwright.Float(30000), // 30 seconds
-	})
-	if err != nil {
-		return nil, fmt.Errorf("failed to navigate to URL: %w", err)
+	if metadata.Authors, source = extractAuthors(doc); len(metadata.Authors) > 0 {
+		metadata.setSource(FieldAuthors, source)
+	}
+	if metadata.Publisher, source = extractPublisher(doc, urlStr); metadata.Publisher != "" {
+		metadata.setSource(FieldPublisher, source)
+	}
+	if metadata.Date, source = extractDate(doc); !metadata.Date.IsZero() {
+		metadata.setSource(FieldDate, source)
+	}
+	if metadata.DOI, source = extractDOI(doc, urlStr); metadata.DOI != "" {
+		metadata.setSource(FieldDOI, source)
 	}
 
-	// Get the page content
-	content, err := page.Content()
-	if err != nil {
-		return nil, fmt.Errorf("failed to get page content: %w", err)
+	// Structured data is more reliable than meta tags where a site has it
+	ld := extractJSONLD(doc)
+	if ld != nil {
+		// Many sites describe every page as a WebPage, which says less than
+		// an og:type of article or video
+		if typeSpecificity(ld.Type) >= typeSpecificity(metadata.Type) {
+			metadata.Type = ld.Type
+			metadata.setSource(FieldType, SourceJSONLD)
+		}
+		if ld.Title != "" {
+			metadata.Title = ld.Title
+			metadata.setSource(FieldTitle, SourceJSONLD)
+		}
+		if len(ld.Authors) > 0 {
+			metadata.Authors = ld.Authors
+			metadata.setSource(FieldAuthors, SourceJSONLD)
+		}
+		if ld.Publisher != "" {
+			metadata.Publisher = ld.Publisher
+			metadata.setSource(FieldPublisher, SourceJSONLD)
+		}
+		if date := ParseDate(ld.DatePublished); !date.IsZero() && date.Precision >= metadata.Date.Precision {
+			metadata.Date = date
+			metadata.setSource(FieldDate, SourceJSONLD)
+		}
 	}
 
-	doc, err := goquery.NewDocumentFromReader(strings.NewReader(content))
-	if err != nil {
-		return nil, fmt.Errorf("failed to parse HTML: %w", err)
+	// A last-modified date is better than none at all, but may not be
+	// when the page was published
+	if metadata.Date.IsZero() {
+		if metadata.Date, source = extractModifiedDate(doc); !metadata.Date.IsZero() {
+			metadata.setConfidence(FieldDate, source, 0.5)
+		}
+	}
+	if metadata.Date.IsZero() && ld != nil {
+		if metadata.Date = ParseDate(ld.DateModified); !metadata.Date.IsZero() {
+			metadata.setConfidence(FieldDate, SourceJSONLD, 0.5)
+		}
 	}
 
-	return parseDocument(doc, urlStr), nil
+	extractCitation(doc, metadata)
+
+	before := metadata.fieldValues()
+	applySiteExtractor(doc, metadata.URL, metadata)
+	metadata.sourceChanges(before, SourceSite)
+
+	return metadata
 }
 
-// parseDocument extracts metadata from a goquery document
-func parseDocument(doc *goquery.Document, urlStr string) *Metadata {
-	metadata := &Metadata{
-		URL:        urlStr,
-		AccessDate: time.Now(),
+// typeSpecificity ranks a metadata type by how much it says about a page:
+// a web page, an article of some kind, or a particular kind of work.
+func typeSpecificity(kind string) int {
+	switch kind {
+	case TypeWebPage:
+		return 0
+	case TypeArticle:
+		return 1
 	}
+	return 2
+}
 
-	metadata.Title = extractTitle(doc)
-	metadata.Author = extractAuthor(doc)
-	metadata.Publisher = extractPublisher(doc, urlStr)
-	metadata.Year = extractYear(doc)
+func extractType(doc *goquery.Document) (string, Source) {
+	ogType := doc.Find(`meta[property="og:type"]`).First().AttrOr("content", "")
 
-	return metadata
+	switch {
+	case strings.HasPrefix(ogType, "video"):
+		return TypeVideo, SourceOpenGraph
+	case ogType == "article":
+		return TypeArticle, SourceOpenGraph
+	}
+
+	return TypeWebPage, SourceDefault
 }
 
-func extractTitle(doc *goquery.Document) string {
+func extractTitle(doc *goquery.Document) (string, Source) {
 	selectors := []string{
 		`meta[property="og:title"]`,
 		`meta[name="twitter:title"]`,
@@ -150,50 +218,123 @@ func extractTitle(doc *goquery.Document) string {
 		if selector == "title" {
 			title := doc.Find(selector).First().Text()
 			if title != "" {
-				return strings.TrimSpace(title)
+				return strings.TrimSpace(title), SourceHTML
 			}
 		} else {
 			title := doc.Find(selector).First().AttrOr("content", "")
 			if title != "" {
-				return strings.TrimSpace(title)
+				return strings.TrimSpace(title), selectorSource(selector)
 			}
 		}
 	}
 
-	return "Untitled"
+	return "Untitled", SourceDefault
 }
 
-func extractAuthor(doc *goquery.Document) string {
+// extractAuthors reads the page's authors. citation_author tags hold one
+// name each; other tags may hold a whole byline such as "By Jane Doe and
+// John Smith".
+func extractAuthors(doc *goquery.Document) ([]bibtex.Name, Source) {
+	authors := []bibtex.Name{}
+	doc.Find(`meta[name="citation_author"]`).Each(func(i int, s *goquery.Selection) {
+		if author := strings.TrimSpace(s.AttrOr("content", "")); author != "" {
+			authors = append(authors, parseAuthorName(author))
+		}
+	})
+	if len(authors) > 0 {
+		return authors, SourceCitation
+	}
+
 	selectors := []string{
 		`meta[name="author"]`,
 		`meta[property="article:author"]`,
-		`meta[name="citation_author"]`,
 		`meta[name="DC.creator"]`,
 		`meta[name="byl"]`,
 	}
 
-	authors := []string{}
-
 	for _, selector := range selectors {
 		doc.Find(selector).Each(func(i int, s *goquery.Selection) {
-			author := s.AttrOr("content", "")
-			if author != "" {
-				authors = append(authors, strings.TrimSpace(author))
-			}
+			authors = append(authors, ParseByline(s.AttrOr("content", ""))...)
 		})
 		if len(authors) > 0 {
-			break
+			return authors, selectorSource(selector)
 		}
 	}
 
+	return authors, SourceDefault
+}
+
+// extractCitation reads the Highwire Press citation_* tags that academic
+// publishers expose, turning the page into a journal article reference.
+func extractCitation(doc *goquery.Document, metadata *Metadata) {
+	content := func(name string) string {
+		return strings.TrimSpace(doc.Find(fmt.Sprintf(`meta[name="%s"]`, name)).First().AttrOr("content", ""))
+	}
+
+	metadata.Journal = content("citation_journal_title")
+	metadata.Volume = content("citation_volume")
+	metadata.Issue = content("citation_issue")
+	metadata.FirstPage = content("citation_firstpage")
+	metadata.LastPage = content("citation_lastpage")
+
+	if metadata.Journal == "" {
+		return
+	}
+
+	metadata.Type = TypeJournalArticle
+	metadata.setSource(FieldType, SourceCitation)
+	metadata.setSource(FieldJournal, SourceCitation)
+
+	if title := content("citation_title"); title != "" {
+		metadata.Title = title
+		metadata.setSource(FieldTitle, SourceCitation)
+	}
+
+	// citation_author tags are repeated once per author, in byline order
+	authors := []bibtex.Name{}
+	doc.Find(`meta[name="citation_author"]`).Each(func(i int, s *goquery.Selection) {
+		if author := strings.TrimSpace(s.AttrOr("content", "")); author != "" {
+			authors = append(authors, parseAuthorName(author))
+		}
+	})
 	if len(authors) > 0 {
-		return strings.Join(authors, " & ")
+		metadata.Authors = authors
+		metadata.setSource(FieldAuthors, SourceCitation)
+	}
+
+	for _, name := range []string{"citation_publication_date", "citation_date", "citation_online_date"} {
+		if date := ParseDate(content(name)); !date.IsZero() {
+			metadata.Date = date
+			metadata.setSource(FieldDate, SourceCitation)
+			break
+		}
+	}
+
+	if publisher := content("citation_publisher"); publisher != "" {
+		metadata.Publisher = publisher
+		metadata.setSource(FieldPublisher, SourceCitation)
+	}
+}
+
+func extractDOI(doc *goquery.Document, urlStr string) (string, Source) {
+	selectors := []string{
+		`meta[name="citation_doi"]`,
+		`meta[name="prism.doi"]`,
+		`meta[name="dc.identifier"]`,
+		`meta[name="DC.identifier"]`,
+		`meta[name="DC.Identifier"]`,
 	}
 
-	return ""
+	for _, selector := range selectors {
+		if d := doi.Normalize(doc.Find(selector).First().AttrOr("content", "")); d != "" {
+			return d, selectorSource(selector)
+		}
+	}
+
+	return doi.Find(urlStr), SourceURL
 }
 
-func extractPublisher(doc *goquery.Document, urlStr string) string {
+func extractPublisher(doc *goquery.Document, urlStr string) (string, Source) {
 	selectors := []string{
 		`meta[property="og:site_name"]`,
 		`meta[name="publisher"]`,
@@ -204,7 +345,7 @@ func extractPublisher(doc *goquery.Document, urlStr string) string {
 	for _, selector := range selectors {
 		publisher := doc.Find(selector).First().AttrOr("content", "")
 		if publisher != "" {
-			return strings.TrimSpace(publisher)
+			return strings.TrimSpace(publisher), selectorSource(selector)
 		}
 	}
 
@@ -212,22 +353,40 @@ func extractPublisher(doc *goquery.Document, urlStr string) string {
 	if err == nil {
 		parts := strings.Split(u.Hostname(), ".")
 		if len(parts) >= 2 {
-			return strings.Title(parts[len(parts)-2])
+			return strings.Title(parts[len(parts)-2]), SourceHostname
 		}
 	}
 
-	return ""
+	return "", SourceDefault
 }
 
-func extractYear(doc *goquery.Document) string {
-	selectors := []string{
+// extractDate returns the publication date of a page. Pages without one
+// are cited as "n.d.", so there is no fallback to the current year.
+func extractDate(doc *goquery.Document) (Date, Source) {
+	return findDate(doc, []string{
 		`meta[name="publication_date"]`,
 		`meta[property="article:published_time"]`,
 		`meta[name="citation_publication_date"]`,
 		`meta[name="DC.date"]`,
+		`meta[name="date"]`,
+		`meta[itemprop="datePublished"]`,
 		`time[datetime]`,
-	}
+	})
+}
+
+// extractModifiedDate returns the date a page was last updated.
+func extractModifiedDate(doc *goquery.Document) (Date, Source) {
+	return findDate(doc, []string{
+		`meta[property="article:modified_time"]`,
+		`meta[property="og:updated_time"]`,
+		`meta[name="last-modified"]`,
+		`meta[itemprop="dateModified"]`,
+	})
+}
 
+// findDate returns the first date found by the selectors, which match either
+// meta tags or <time datetime> elements, and its source.
+func findDate(doc *goquery.Document, selectors []string) (Date, Source) {
 	for _, selector := range selectors {
 		var dateStr string
 		if selector == `time[datetime]` {
@@ -236,66 +395,28 @@ func extractYear(doc *goquery.Document) string {
 			dateStr = doc.Find(selector).First().AttrOr("content", "")
 		}
 
-		if dateStr != "" {
-			if year := extractYearFromDate(dateStr); year != "" {
-				return year
-			}
+		if date := ParseDate(dateStr); !date.IsZero() {
+			return date, selectorSource(selector)
 		}
 	}
 
-	return fmt.Sprintf("%d", time.Now().Year())
+	return Date{}, SourceDefault
 }
 
-func extractYearFromDate(dateStr string) string {
-	layouts := []string{
-		"2006-01-02T15:04:05Z07:00",
-		"2006-01-02T15:04:05",
-		"2006-01-02",
-		"2006/01/02",
-		"02/01/2006",
-		"01/02/2006",
-		"2006",
-	}
-
-	for _, layout := range layouts {
-		if t, err := time.Parse(layout, dateStr); err == nil {
-			return fmt.Sprintf("%d", t.Year())
-		}
-	}
-
-	if len(dateStr) >= 4 {
-		yearStr := dateStr[:4]
-		if _, err := time.Parse("2006", yearStr); err == nil {
-			return yearStr
-		}
-	}
-
-	return ""
+func (m *Metadata) ToAPAFormat() (string, error) {
+	return m.ToAPAFormatLocale(apa.English)
 }
 
-func (m *Metadata) ToAPAFormat() string {
-	author := m.Author
-	if author == "" {
-		author = m.Publisher
-	}
-	if author == "" {
-		author = "Unknown"
-	}
-
-	title := m.Title
-	if title == "" {
-		title = "Untitled"
+// ToAPAFormatLocale renders the reference using the terms and month names of
+// the given locale. The metadata goes through the same formatter as BibTeX
+// entries.
+func (m *Metadata) ToAPAFormatLocale(loc *apa.Locale) (string, error) {
+	result, err := apa.FormatLocale(m.ToEntry(), loc)
+	if err != nil {
+		return "", fmt.Errorf("failed to format reference: %w", err)
 	}
-
-	result := fmt.Sprintf("%s. (%s). %s", author, m.Year, title)
-
-	if m.Publisher != "" && m.Publisher != author {
-		result += fmt.Sprintf(". %s", m.Publisher)
+	if result == "" {
+		return "", fmt.Errorf("failed to format reference: empty citation")
 	}
-
-	result += fmt.Sprintf(". Retrieved %s, from %s",
-		m.AccessDate.Format("January 2, 2006"),
-		m.URL)
-
-	return result
+	return result, nil
 }
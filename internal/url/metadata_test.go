@@ -0,0 +1,63 @@
+package url
+
+import (
+	"bytes"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestParseHTMLCharset(t *testing.T) {
+	tests := []struct {
+		fixture     string
+		contentType string
+	}{
+		// The title is past the 1024 bytes the charset sniffer reads
+		{"undeclared-utf8.html", ""},
+		{"undeclared-utf8.html", "text/html"},
+		{"undeclared-latin1.html", ""},
+		{"declared-latin1.html", ""},
+		{"declared-latin1.html", "text/html; charset=iso-8859-1"},
+		// A charset in the header wins over the default
+		{"undeclared-latin1.html", "text/html; charset=windows-1252"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.fixture+" "+tt.contentType, func(t *testing.T) {
+			body, err := os.ReadFile(filepath.Join("testdata", "charset", tt.fixture))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			page := &Page{URL: "https://example.com/", Header: http.Header{}, Body: body}
+			if tt.contentType != "" {
+				page.Header.Set("Content-Type", tt.contentType)
+			}
+			fromPage, err := ParsePage(page, page.URL)
+			if err != nil {
+				t.Fatalf("ParsePage() error = %v", err)
+			}
+			checkCharset(t, "ParsePage", fromPage)
+
+			if tt.contentType == "" {
+				fromHTML, err := ParseHTML(bytes.NewReader(body), page.URL)
+				if err != nil {
+					t.Fatalf("ParseHTML() error = %v", err)
+				}
+				checkCharset(t, "ParseHTML", fromHTML)
+			}
+		})
+	}
+}
+
+func checkCharset(t *testing.T, fn string, metadata *Metadata) {
+	t.Helper()
+
+	if metadata.Title != "Café Müller" {
+		t.Errorf("%s() Title = %q, want Café Müller", fn, metadata.Title)
+	}
+	if len(metadata.Authors) != 1 || metadata.Authors[0].Family != "Groß" {
+		t.Errorf("%s() Authors = %v, want Groß", fn, metadata.Authors)
+	}
+}
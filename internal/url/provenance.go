@@ -0,0 +1,143 @@
+package url
+
+import (
+	"reflect"
+	"strings"
+)
+
+// Source identifies where a metadata field was found.
+type Source string
+
+const (
+	SourceCitation  Source = "citation"  // Highwire Press citation_* meta tags
+	SourceJSONLD    Source = "json-ld"   // schema.org structured data
+	SourceSite      Source = "site"      // a site-specific extractor
+	SourceOpenGraph Source = "opengraph" // og: and article: meta tags
+	SourceMeta      Source = "meta"      // other meta tags: Twitter, Dublin Core, name="author"
+	SourceHTML      Source = "html"      // page markup such as <title> and <time>
+	SourceURL       Source = "url"       // the page's URL
+	SourceHostname  Source = "hostname"  // a guess from the hostname
+	SourceDefault   Source = "default"   // a placeholder
+	SourceUser      Source = "user"      // entered or confirmed by the user
+)
+
+// sourceConfidence is how far a field from each source is trusted, from 0 to
+// 1. Publishers' citation tags are written for reference managers; a
+// hostname guess is often wrong.
+var sourceConfidence = map[Source]float64{
+	SourceUser:      1,
+	SourceCitation:  0.95,
+	SourceJSONLD:    0.9,
+	SourceSite:      0.9,
+	SourceOpenGraph: 0.8,
+	SourceURL:       0.8,
+	SourceMeta:      0.7,
+	SourceHTML:      0.6,
+	SourceHostname:  0.3,
+	SourceDefault:   0,
+}
+
+// DefaultConfidenceThreshold is the confidence below which a field should be
+// confirmed by the user.
+const DefaultConfidenceThreshold = 0.6
+
+// Fields whose provenance is recorded.
+const (
+	FieldType      = "type"
+	FieldTitle     = "title"
+	FieldAuthors   = "authors"
+	FieldDate      = "date"
+	FieldPublisher = "publisher"
+	FieldJournal   = "journal"
+	FieldDOI       = "doi"
+)
+
+// reviewFields are the fields LowConfidence reports, in the order they are
+// shown, including ones that were not found at all.
+var reviewFields = []string{FieldTitle, FieldAuthors, FieldDate, FieldPublisher}
+
+// Provenance records where a field came from and how far it is trusted.
+type Provenance struct {
+	Source     Source
+	Confidence float64
+}
+
+// setSource records the source of a field with the source's usual
+// confidence.
+func (m *Metadata) setSource(field string, source Source) {
+	m.setConfidence(field, source, sourceConfidence[source])
+}
+
+// setConfidence records the source of a field with a specific confidence.
+func (m *Metadata) setConfidence(field string, source Source, confidence float64) {
+	if m.Provenance == nil {
+		m.Provenance = make(map[string]Provenance)
+	}
+	m.Provenance[field] = Provenance{Source: source, Confidence: confidence}
+}
+
+// Confidence returns how far a field is trusted, or 0 if it was not found.
+func (m *Metadata) Confidence(field string) float64 {
+	if p, ok := m.Provenance[field]; ok {
+		return p.Confidence
+	}
+	return 0
+}
+
+// LowConfidence returns the title, authors, date and publisher fields with a
+// confidence below threshold, including missing ones.
+func (m *Metadata) LowConfidence(threshold float64) []string {
+	fields := []string{}
+	for _, field := range reviewFields {
+		if m.Confidence(field) < threshold {
+			fields = append(fields, field)
+		}
+	}
+	return fields
+}
+
+// fieldValues returns the provenance-tracked fields of m for comparison.
+func (m *Metadata) fieldValues() map[string]interface{} {
+	return map[string]interface{}{
+		FieldType:      m.Type,
+		FieldTitle:     m.Title,
+		FieldAuthors:   m.Authors,
+		FieldDate:      m.Date,
+		FieldPublisher: m.Publisher,
+		FieldJournal:   m.Journal,
+		FieldDOI:       m.DOI,
+	}
+}
+
+// sourceChanges attributes the fields that differ from before to source.
+func (m *Metadata) sourceChanges(before map[string]interface{}, source Source) {
+	for field, value := range m.fieldValues() {
+		if !reflect.DeepEqual(before[field], value) {
+			m.setSource(field, source)
+		}
+	}
+}
+
+// titleConfidence lowers the confidence of a <title> that looks like it
+// includes the site name, as in "Article | Site".
+func titleConfidence(title string) float64 {
+	for _, sep := range []string{" | ", " - ", " – ", " — ", " :: "} {
+		if strings.Contains(title, sep) {
+			return 0.4
+		}
+	}
+	return sourceConfidence[SourceHTML]
+}
+
+// selectorSource returns the source of a meta tag or element selector.
+func selectorSource(selector string) Source {
+	switch {
+	case strings.Contains(selector, `"og:`), strings.Contains(selector, `"article:`):
+		return SourceOpenGraph
+	case strings.Contains(selector, `"citation_`):
+		return SourceCitation
+	case strings.HasPrefix(selector, "meta") && !strings.Contains(selector, "itemprop"):
+		return SourceMeta
+	}
+	return SourceHTML
+}
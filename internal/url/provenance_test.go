@@ -0,0 +1,237 @@
+package url
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/knhn1004/bibtext-to-apa6/internal/bibtex"
+)
+
+func TestSetSource(t *testing.T) {
+	m := &Metadata{}
+	if got := m.Confidence(FieldTitle); got != 0 {
+		t.Errorf("Confidence() of a missing field = %v, want 0", got)
+	}
+
+	m.setSource(FieldTitle, SourceCitation)
+	if got := m.Provenance[FieldTitle]; got != (Provenance{Source: SourceCitation, Confidence: 0.95}) {
+		t.Errorf("Provenance = %+v, want citation at 0.95", got)
+	}
+
+	// A later source replaces an earlier one
+	m.setConfidence(FieldTitle, SourceHTML, 0.4)
+	if got := m.Confidence(FieldTitle); got != 0.4 {
+		t.Errorf("Confidence() = %v, want 0.4", got)
+	}
+	m.setSource(FieldTitle, SourceUser)
+	if got := m.Provenance[FieldTitle]; got != (Provenance{Source: SourceUser, Confidence: 1}) {
+		t.Errorf("Provenance = %+v, want user at 1", got)
+	}
+}
+
+func TestSourceChanges(t *testing.T) {
+	m := &Metadata{
+		Type:    TypeWebPage,
+		Title:   "Braided river - Wikipedia",
+		Authors: []bibtex.Name{{Family: "Doe", Given: "Jane"}},
+		Date:    Date{Year: 2020, Precision: DateYear},
+	}
+	m.setSource(FieldType, SourceDefault)
+	m.setSource(FieldTitle, SourceHTML)
+	m.setSource(FieldAuthors, SourceMeta)
+	m.setSource(FieldDate, SourceMeta)
+
+	before := m.fieldValues()
+	m.Type = TypeWiki
+	m.Title = "Braided river"
+	m.Authors = []bibtex.Name{{Family: "Doe", Given: "Jane"}}
+	m.Date = Date{}
+	m.sourceChanges(before, SourceSite)
+
+	want := map[string]Provenance{
+		FieldType:    {Source: SourceSite, Confidence: 0.9},
+		FieldTitle:   {Source: SourceSite, Confidence: 0.9},
+		FieldAuthors: {Source: SourceMeta, Confidence: 0.7},
+		FieldDate:    {Source: SourceSite, Confidence: 0.9},
+	}
+	if !reflect.DeepEqual(m.Provenance, want) {
+		t.Errorf("Provenance = %+v, want %+v", m.Provenance, want)
+	}
+}
+
+func TestTitleConfidence(t *testing.T) {
+	tests := []struct {
+		title string
+		want  float64
+	}{
+		{"Rivers of the Pacific Northwest", 0.6},
+		{"Rivers of the Pacific Northwest | Field Notes", 0.4},
+		{"Rivers of the Pacific Northwest - Field Notes", 0.4},
+		{"Rivers of the Pacific Northwest – Field Notes", 0.4},
+		{"Field Notes :: Rivers", 0.4},
+		{"Well-known rivers", 0.6},
+	}
+
+	for _, tt := range tests {
+		if got := titleConfidence(tt.title); got != tt.want {
+			t.Errorf("titleConfidence(%q) = %v, want %v", tt.title, got, tt.want)
+		}
+	}
+}
+
+func TestLowConfidence(t *testing.T) {
+	m := &Metadata{}
+	if got, want := m.LowConfidence(DefaultConfidenceThreshold), []string{FieldTitle, FieldAuthors, FieldDate, FieldPublisher}; !reflect.DeepEqual(got, want) {
+		t.Errorf("LowConfidence() with nothing found = %v, want %v", got, want)
+	}
+
+	m.setSource(FieldTitle, SourceHTML)
+	m.setSource(FieldAuthors, SourceMeta)
+	m.setConfidence(FieldDate, SourceOpenGraph, 0.5)
+	m.setSource(FieldPublisher, SourceHostname)
+	m.setSource(FieldType, SourceDefault)
+
+	// The threshold itself is confident enough, and the type is not reviewed
+	if got, want := m.LowConfidence(DefaultConfidenceThreshold), []string{FieldDate, FieldPublisher}; !reflect.DeepEqual(got, want) {
+		t.Errorf("LowConfidence(%v) = %v, want %v", DefaultConfidenceThreshold, got, want)
+	}
+	if got := m.LowConfidence(0); len(got) != 0 {
+		t.Errorf("LowConfidence(0) = %v, want none", got)
+	}
+}
+
+// TestParseProvenance checks the source and confidence recorded for each
+// field of fixture pages, including the placeholders used when a field is
+// not found.
+func TestParseProvenance(t *testing.T) {
+	tests := []struct {
+		fixture       string
+		url           string
+		wantTitle     string
+		wantPublisher string
+		want          map[string]Provenance
+		wantLow       []string
+	}{
+		{
+			// Nothing to go on but the hostname. Undated pages are cited as
+			// n.d., so no date is made up
+			fixture:       "provenance/bare.html",
+			url:           "https://www.fieldnotes.com/rivers",
+			wantTitle:     "Untitled",
+			wantPublisher: "Fieldnotes",
+			want: map[string]Provenance{
+				FieldType:      {Source: SourceDefault, Confidence: 0},
+				FieldTitle:     {Source: SourceDefault, Confidence: 0},
+				FieldPublisher: {Source: SourceHostname, Confidence: 0.3},
+			},
+			wantLow: []string{FieldTitle, FieldAuthors, FieldDate, FieldPublisher},
+		},
+		{
+			fixture:       "provenance/title-site.html",
+			url:           "https://www.fieldnotes.com/rivers",
+			wantTitle:     "Rivers of the Pacific Northwest | Field Notes",
+			wantPublisher: "Fieldnotes",
+			want: map[string]Provenance{
+				FieldType:      {Source: SourceDefault, Confidence: 0},
+				FieldTitle:     {Source: SourceHTML, Confidence: 0.4},
+				FieldPublisher: {Source: SourceHostname, Confidence: 0.3},
+			},
+			wantLow: []string{FieldTitle, FieldAuthors, FieldDate, FieldPublisher},
+		},
+		{
+			// A last-modified date is trusted less than a publication date
+			fixture:       "provenance/modified.html",
+			url:           "https://www.fieldnotes.com/rivers",
+			wantTitle:     "Rivers of the Pacific Northwest",
+			wantPublisher: "Field Notes",
+			want: map[string]Provenance{
+				FieldType:      {Source: SourceDefault, Confidence: 0},
+				FieldTitle:     {Source: SourceHTML, Confidence: 0.6},
+				FieldAuthors:   {Source: SourceMeta, Confidence: 0.7},
+				FieldDate:      {Source: SourceOpenGraph, Confidence: 0.5},
+				FieldPublisher: {Source: SourceMeta, Confidence: 0.7},
+			},
+			wantLow: []string{FieldDate},
+		},
+		{
+			fixture:       "pages/article.html",
+			url:           "https://www.fieldnotes.com/rivers",
+			wantTitle:     "Rivers of the Pacific Northwest",
+			wantPublisher: "Field Notes",
+			want: map[string]Provenance{
+				FieldType:      {Source: SourceOpenGraph, Confidence: 0.8},
+				FieldTitle:     {Source: SourceOpenGraph, Confidence: 0.8},
+				FieldAuthors:   {Source: SourceMeta, Confidence: 0.7},
+				FieldDate:      {Source: SourceOpenGraph, Confidence: 0.8},
+				FieldPublisher: {Source: SourceOpenGraph, Confidence: 0.8},
+			},
+			wantLow: []string{},
+		},
+		{
+			// Structured data overrides the meta tags
+			fixture:       "provenance/jsonld.html",
+			url:           "https://www.fieldnotes.com/rivers",
+			wantTitle:     "Rivers of the Pacific Northwest",
+			wantPublisher: "Field Notes",
+			want: map[string]Provenance{
+				FieldType:      {Source: SourceJSONLD, Confidence: 0.9},
+				FieldTitle:     {Source: SourceJSONLD, Confidence: 0.9},
+				FieldAuthors:   {Source: SourceJSONLD, Confidence: 0.9},
+				FieldDate:      {Source: SourceJSONLD, Confidence: 0.9},
+				FieldPublisher: {Source: SourceJSONLD, Confidence: 0.9},
+			},
+			wantLow: []string{},
+		},
+		{
+			// Journals rarely name their publisher in citation tags
+			fixture:       "pages/journal.html",
+			url:           "https://www.fieldnotes.com/rivers",
+			wantTitle:     "Sediment transport in braided rivers",
+			wantPublisher: "Fieldnotes",
+			want: map[string]Provenance{
+				FieldType:      {Source: SourceCitation, Confidence: 0.95},
+				FieldTitle:     {Source: SourceCitation, Confidence: 0.95},
+				FieldAuthors:   {Source: SourceCitation, Confidence: 0.95},
+				FieldDate:      {Source: SourceCitation, Confidence: 0.95},
+				FieldPublisher: {Source: SourceHostname, Confidence: 0.3},
+				FieldJournal:   {Source: SourceCitation, Confidence: 0.95},
+				FieldDOI:       {Source: SourceCitation, Confidence: 0.95},
+			},
+			wantLow: []string{FieldPublisher},
+		},
+		{
+			// A missing author and date are what the site rules expect
+			fixture:       "sites/wikipedia.html",
+			url:           "https://en.wikipedia.org/wiki/Braided_river",
+			wantTitle:     "Braided river",
+			wantPublisher: "Wikipedia",
+			want: map[string]Provenance{
+				FieldType:      {Source: SourceSite, Confidence: 0.9},
+				FieldTitle:     {Source: SourceSite, Confidence: 0.9},
+				FieldAuthors:   {Source: SourceSite, Confidence: 0.9},
+				FieldDate:      {Source: SourceSite, Confidence: 0.9},
+				FieldPublisher: {Source: SourceSite, Confidence: 0.9},
+			},
+			wantLow: []string{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.fixture, func(t *testing.T) {
+			m := parseDocument(loadDocument(t, tt.fixture), tt.url)
+
+			if m.Title != tt.wantTitle {
+				t.Errorf("Title = %q, want %q", m.Title, tt.wantTitle)
+			}
+			if m.Publisher != tt.wantPublisher {
+				t.Errorf("Publisher = %q, want %q", m.Publisher, tt.wantPublisher)
+			}
+			if !reflect.DeepEqual(m.Provenance, tt.want) {
+				t.Errorf("Provenance = %+v\nwant %+v", m.Provenance, tt.want)
+			}
+			if got := m.LowConfidence(DefaultConfidenceThreshold); !reflect.DeepEqual(got, tt.wantLow) {
+				t.Errorf("LowConfidence() = %v, want %v", got, tt.wantLow)
+			}
+		})
+	}
+}
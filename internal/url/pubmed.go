@@ -0,0 +1,193 @@
+package url
+
+import (
+	"context"
+	"encoding/xml"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/knhn1004/bibtext-to-apa6/internal/bibtex"
+	"github.com/knhn1004/bibtext-to-apa6/internal/doi"
+)
+
+// DefaultPubMedBaseURL is the NCBI E-utilities endpoint.
+const DefaultPubMedBaseURL = "https://eutils.ncbi.nlm.nih.gov/entrez/eutils"
+
+// PubMedResolver looks up PubMed IDs through NCBI E-utilities.
+type PubMedResolver struct {
+	BaseURL string
+	Client  *http.Client
+	APIKey  string // optional NCBI API key for higher rate limits
+}
+
+// NewPubMedResolver returns a resolver that queries eutils.ncbi.nlm.nih.gov.
+func NewPubMedResolver() *PubMedResolver {
+	return &PubMedResolver{
+		BaseURL: DefaultPubMedBaseURL,
+		Client:  &http.Client{Timeout: 15 * time.Second},
+	}
+}
+
+type pubmedArticleSet struct {
+	Articles []pubmedArticle `xml:"PubmedArticle"`
+}
+
+type pubmedArticle struct {
+	Article struct {
+		Journal struct {
+			Title        string `xml:"Title"`
+			JournalIssue struct {
+				Volume  string `xml:"Volume"`
+				Issue   string `xml:"Issue"`
+				PubDate struct {
+					Year        string `xml:"Year"`
+					MedlineDate string `xml:"MedlineDate"`
+				} `xml:"PubDate"`
+			} `xml:"JournalIssue"`
+		} `xml:"Journal"`
+		ArticleTitle string `xml:"ArticleTitle"`
+		Pagination   struct {
+			MedlinePgn string `xml:"MedlinePgn"`
+		} `xml:"Pagination"`
+		ELocationIDs []struct {
+			Type  string `xml:"EIdType,attr"`
+			Value string `xml:",chardata"`
+		} `xml:"ELocationID"`
+		Authors []struct {
+			LastName       string `xml:"LastName"`
+			ForeName       string `xml:"ForeName"`
+			Initials       string `xml:"Initials"`
+			CollectiveName string `xml:"CollectiveName"`
+		} `xml:"AuthorList>Author"`
+	} `xml:"MedlineCitation>Article"`
+	ArticleIDs []struct {
+		Type  string `xml:"IdType,attr"`
+		Value string `xml:",chardata"`
+	} `xml:"PubmedData>ArticleIdList>ArticleId"`
+}
+
+// Resolve fetches the PubMed record for a PMID.
+func (r *PubMedResolver) Resolve(ctx context.Context, pmid string) (*bibtex.Entry, error) {
+	baseURL := strings.TrimRight(r.BaseURL, "/")
+	if baseURL == "" {
+		baseURL = DefaultPubMedBaseURL
+	}
+
+	query := url.Values{
+		"db":      {"pubmed"},
+		"id":      {pmid},
+		"retmode": {"xml"},
+	}
+	if r.APIKey != "" {
+		query.Set("api_key", r.APIKey)
+	}
+
+	body, err := getBody(ctx, r.Client, baseURL+"/efetch.fcgi?"+query.Encode(), "application/xml")
+	if err != nil {
+		return nil, err
+	}
+
+	var set pubmedArticleSet
+	if err := xml.Unmarshal(body, &set); err != nil {
+		return nil, fmt.Errorf("failed to parse PubMed response: %w", err)
+	}
+
+	if len(set.Articles) == 0 {
+		return nil, fmt.Errorf("PubMed ID not found: %s", pmid)
+	}
+
+	return set.Articles[0].toEntry(pmid), nil
+}
+
+func (p *pubmedArticle) toEntry(pmid string) *bibtex.Entry {
+	entry := &bibtex.Entry{
+		Type:   "article",
+		Fields: make(map[string]string),
+	}
+
+	set := func(field, value string) {
+		if value = strings.TrimSpace(value); value != "" {
+			entry.Fields[field] = value
+		}
+	}
+
+	article := p.Article
+
+	authors := []string{}
+	for _, a := range article.Authors {
+		switch {
+		case a.LastName != "" && a.ForeName != "":
+			authors = append(authors, fmt.Sprintf("%s, %s", a.LastName, a.ForeName))
+		case a.LastName != "" && a.Initials != "":
+			authors = append(authors, fmt.Sprintf("%s, %s", a.LastName, a.Initials))
+		case a.LastName != "":
+			authors = append(authors, a.LastName)
+		case a.CollectiveName != "":
+			authors = append(authors, bibtex.Name{Family: a.CollectiveName, Corporate: true}.String())
+		}
+	}
+	set("author", strings.Join(authors, " and "))
+
+	// PubMed titles end with a period that the formatter adds itself
+	set("title", strings.TrimSuffix(strings.TrimSpace(article.ArticleTitle), "."))
+	set("journal", article.Journal.Title)
+	set("volume", article.Journal.JournalIssue.Volume)
+	set("number", article.Journal.JournalIssue.Issue)
+	set("pages", expandMedlinePages(article.Pagination.MedlinePgn))
+	set("pmid", pmid)
+
+	pubDate := article.Journal.JournalIssue.PubDate
+	if pubDate.Year != "" {
+		set("year", pubDate.Year)
+	} else {
+		set("year", yearPattern.FindString(pubDate.MedlineDate))
+	}
+
+	for _, id := range article.ELocationIDs {
+		if id.Type == "doi" {
+			set("doi", doi.Normalize(id.Value))
+		}
+	}
+	if entry.GetField("doi") == "" {
+		for _, id := range p.ArticleIDs {
+			if id.Type == "doi" {
+				set("doi", doi.Normalize(id.Value))
+			}
+		}
+	}
+
+	entry.Key = entry.GenerateKey("pmid")
+
+	return entry
+}
+
+// expandMedlinePages expands MEDLINE's abbreviated page ranges, so that
+// "1021-9" becomes "1021-1029" as APA requires.
+func expandMedlinePages(pages string) string {
+	parts := strings.SplitN(strings.TrimSpace(pages), "-", 2)
+	if len(parts) != 2 {
+		return pages
+	}
+
+	first, last := parts[0], parts[1]
+	if len(last) < len(first) && isDigits(first) && isDigits(last) {
+		last = first[:len(first)-len(last)] + last
+	}
+
+	return first + "-" + last
+}
+
+func isDigits(s string) bool {
+	if s == "" {
+		return false
+	}
+	for _, c := range s {
+		if c < '0' || c > '9' {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,153 @@
+package url
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/knhn1004/bibtext-to-apa6/internal/bibtex"
+)
+
+// fieldLabels are the prompts shown for reviewed fields.
+var fieldLabels = map[string]string{
+	FieldTitle:     "Title",
+	FieldAuthors:   "Authors",
+	FieldDate:      "Date (YYYY, YYYY-MM or YYYY-MM-DD)",
+	FieldPublisher: "Publisher",
+}
+
+// sourceLabels describe sources to the user.
+var sourceLabels = map[Source]string{
+	SourceCitation:  "citation tags",
+	SourceJSONLD:    "structured data",
+	SourceSite:      "site rules",
+	SourceOpenGraph: "Open Graph tags",
+	SourceMeta:      "meta tags",
+	SourceHTML:      "page HTML",
+	SourceURL:       "the URL",
+	SourceHostname:  "guessed from the hostname",
+	SourceDefault:   "placeholder",
+	SourceUser:      "entered by you",
+}
+
+// Review shows the fields of m with a confidence below threshold on out and
+// reads corrections from in, one line per field. An empty line keeps the
+// value, "-" clears it, and corrected fields are marked as SourceUser. It
+// returns the fields that were changed.
+func Review(in io.Reader, out io.Writer, m *Metadata, threshold float64) ([]string, error) {
+	fields := m.LowConfidence(threshold)
+	if len(fields) == 0 {
+		return nil, nil
+	}
+
+	fmt.Fprintln(out, "Some details of this reference may be wrong or missing.")
+	fmt.Fprintln(out, `Press Enter to keep a value, or type "-" to clear it.`)
+
+	reader := bufio.NewReader(in)
+	changed := []string{}
+
+	for _, field := range fields {
+		for {
+			fmt.Fprintf(out, "%s [%s] (%s): ", fieldLabels[field], m.fieldString(field), m.sourceLabel(field))
+
+			line, err := reader.ReadString('\n')
+			if err != nil && err != io.EOF {
+				return changed, fmt.Errorf("failed to read input: %w", err)
+			}
+			answer := strings.TrimSpace(line)
+
+			if answer == "" {
+				if err == io.EOF {
+					return changed, nil
+				}
+				break
+			}
+
+			if setErr := m.setField(field, answer); setErr != nil {
+				fmt.Fprintf(out, "  %v\n", setErr)
+				if err == io.EOF {
+					return changed, nil
+				}
+				continue
+			}
+
+			m.setSource(field, SourceUser)
+			changed = append(changed, field)
+			break
+		}
+	}
+
+	return changed, nil
+}
+
+// fieldString renders a reviewed field for display.
+func (m *Metadata) fieldString(field string) string {
+	switch field {
+	case FieldTitle:
+		return m.Title
+	case FieldAuthors:
+		names := []string{}
+		for _, name := range m.Authors {
+			names = append(names, name.String())
+		}
+		return strings.Join(names, "; ")
+	case FieldDate:
+		return m.Date.String()
+	case FieldPublisher:
+		return m.Publisher
+	}
+	return ""
+}
+
+// setField sets a reviewed field from user input; "-" clears it.
+func (m *Metadata) setField(field, value string) error {
+	clear := value == "-"
+
+	switch field {
+	case FieldTitle:
+		if clear {
+			return fmt.Errorf("a reference needs a title")
+		}
+		m.Title = value
+	case FieldAuthors:
+		if clear {
+			m.Authors = nil
+			return nil
+		}
+		authors := []bibtex.Name{}
+		for _, part := range strings.Split(value, ";") {
+			authors = append(authors, ParseByline(part)...)
+		}
+		if len(authors) == 0 {
+			return fmt.Errorf("no names found; separate names with \";\"")
+		}
+		m.Authors = authors
+	case FieldDate:
+		if clear {
+			m.Date = Date{}
+			return nil
+		}
+		date := ParseDate(value)
+		if date.IsZero() {
+			return fmt.Errorf("unrecognized date: %s", value)
+		}
+		m.Date = date
+	case FieldPublisher:
+		if clear {
+			m.Publisher = ""
+			return nil
+		}
+		m.Publisher = value
+	}
+
+	return nil
+}
+
+func (m *Metadata) sourceLabel(field string) string {
+	p, ok := m.Provenance[field]
+	if !ok {
+		return "not found"
+	}
+	return fmt.Sprintf("%s, %.0f%% confidence", sourceLabels[p.Source], p.Confidence*100)
+}
@@ -0,0 +1,136 @@
+package url
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/knhn1004/bibtext-to-apa6/internal/bibtex"
+)
+
+func TestReview(t *testing.T) {
+	tests := []struct {
+		name          string
+		input         string
+		wantChanged   []string
+		wantTitle     string
+		wantAuthors   []bibtex.Name
+		wantDate      string
+		wantPublisher string
+		wantOutput    []string
+	}{
+		{
+			name:          "keep",
+			input:         "\n\n\n\n",
+			wantChanged:   []string{},
+			wantTitle:     "Untitled",
+			wantPublisher: "Fieldnotes",
+			wantOutput:    []string{"Title [Untitled] (placeholder, 0% confidence): ", "Authors [] (not found): ", "Publisher [Fieldnotes] (guessed from the hostname, 30% confidence): "},
+		},
+		{
+			name:          "correct",
+			input:         "Rivers of the Pacific Northwest\nAda Lovelace; Hopper, Grace\n2022-09-14\nField Notes\n",
+			wantChanged:   []string{FieldTitle, FieldAuthors, FieldDate, FieldPublisher},
+			wantTitle:     "Rivers of the Pacific Northwest",
+			wantAuthors:   []bibtex.Name{{Family: "Lovelace", Given: "Ada"}, {Family: "Hopper", Given: "Grace"}},
+			wantDate:      "2022-09-14",
+			wantPublisher: "Field Notes",
+		},
+		{
+			// A title cannot be cleared, so it is asked for again
+			name:        "clear",
+			input:       "-\nRivers\n-\n-\n-\n",
+			wantChanged: []string{FieldTitle, FieldAuthors, FieldDate, FieldPublisher},
+			wantTitle:   "Rivers",
+			wantOutput:  []string{"  a reference needs a title\n"},
+		},
+		{
+			name:          "retry",
+			input:         "\n\nsoon\nSeptember 2022\n\n",
+			wantChanged:   []string{FieldDate},
+			wantTitle:     "Untitled",
+			wantDate:      "2022-09",
+			wantPublisher: "Fieldnotes",
+			wantOutput:    []string{"  unrecognized date: soon\nDate (YYYY, YYYY-MM or YYYY-MM-DD) [] (not found): "},
+		},
+		{
+			// The end of input keeps the remaining fields
+			name:          "eof",
+			input:         "Rivers",
+			wantChanged:   []string{FieldTitle},
+			wantTitle:     "Rivers",
+			wantPublisher: "Fieldnotes",
+		},
+		{
+			name:          "eof after bad input",
+			input:         "\n\nsoon",
+			wantChanged:   []string{},
+			wantTitle:     "Untitled",
+			wantPublisher: "Fieldnotes",
+			wantOutput:    []string{"  unrecognized date: soon\n"},
+		},
+		{
+			name:          "no input",
+			input:         "",
+			wantChanged:   []string{},
+			wantTitle:     "Untitled",
+			wantPublisher: "Fieldnotes",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := parseDocument(loadDocument(t, "provenance/bare.html"), "https://www.fieldnotes.com/rivers")
+
+			var out bytes.Buffer
+			changed, err := Review(strings.NewReader(tt.input), &out, m, DefaultConfidenceThreshold)
+			if err != nil {
+				t.Fatalf("Review() error = %v", err)
+			}
+			if !reflect.DeepEqual(changed, tt.wantChanged) {
+				t.Errorf("Review() = %v, want %v", changed, tt.wantChanged)
+			}
+
+			if m.Title != tt.wantTitle {
+				t.Errorf("Title = %q, want %q", m.Title, tt.wantTitle)
+			}
+			if len(m.Authors) != len(tt.wantAuthors) || (len(m.Authors) > 0 && !reflect.DeepEqual(m.Authors, tt.wantAuthors)) {
+				t.Errorf("Authors = %+v, want %+v", m.Authors, tt.wantAuthors)
+			}
+			if got := m.Date.String(); got != tt.wantDate {
+				t.Errorf("Date = %q, want %q", got, tt.wantDate)
+			}
+			if m.Publisher != tt.wantPublisher {
+				t.Errorf("Publisher = %q, want %q", m.Publisher, tt.wantPublisher)
+			}
+
+			for _, field := range changed {
+				if got := m.Provenance[field]; got != (Provenance{Source: SourceUser, Confidence: 1}) {
+					t.Errorf("Provenance[%s] = %+v, want entered by the user", field, got)
+				}
+			}
+			for _, want := range tt.wantOutput {
+				if !strings.Contains(out.String(), want) {
+					t.Errorf("output does not contain %q:\n%s", want, out.String())
+				}
+			}
+		})
+	}
+}
+
+func TestReviewConfident(t *testing.T) {
+	m := parseDocument(loadDocument(t, "pages/article.html"), "https://www.fieldnotes.com/rivers")
+
+	var out bytes.Buffer
+	changed, err := Review(strings.NewReader("Other title\n"), &out, m, DefaultConfidenceThreshold)
+	if err != nil || changed != nil {
+		t.Errorf("Review() = %v, %v, want nothing to review", changed, err)
+	}
+	if out.Len() != 0 {
+		t.Errorf("Review() wrote %q, want no prompts", out.String())
+	}
+	if m.Title != "Rivers of the Pacific Northwest" {
+		t.Errorf("Title = %q, want it unchanged", m.Title)
+	}
+}
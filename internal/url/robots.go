@@ -0,0 +1,260 @@
+package url
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+)
+
+// DefaultRobotsAgent is the product token matched against User-agent lines
+// in robots.txt.
+const DefaultRobotsAgent = "bibapa"
+
+// maxRobotsSize caps how much of a robots.txt file is read, as crawlers are
+// allowed to by RFC 9309.
+const maxRobotsSize = 500 << 10
+
+// ErrDisallowed is returned when robots.txt does not allow fetching a URL.
+var ErrDisallowed = errors.New("disallowed by robots.txt")
+
+// Robots checks URLs against the robots.txt of their sites, caching each
+// site's rules for the lifetime of the Robots.
+type Robots struct {
+	Agent     string
+	UserAgent string
+	Client    *http.Client
+
+	mu    sync.Mutex
+	sites map[string]*robotsRules
+}
+
+// NewRobots returns a Robots that fetches robots.txt with client and obeys
+// the rules for DefaultRobotsAgent.
+func NewRobots(client *http.Client) *Robots {
+	return &Robots{
+		Agent:     DefaultRobotsAgent,
+		UserAgent: defaultUserAgent,
+		Client:    client,
+	}
+}
+
+// robotsRules are the rules of one robots.txt group.
+type robotsRules struct {
+	disallowAll bool
+	rules       []robotsRule
+}
+
+type robotsRule struct {
+	allow   bool
+	pattern string
+}
+
+// Check returns an error wrapping ErrDisallowed if robots.txt does not allow
+// fetching urlStr.
+func (r *Robots) Check(ctx context.Context, urlStr string) error {
+	u, err := url.Parse(urlStr)
+	if err != nil || u.Host == "" {
+		return fmt.Errorf("invalid URL: %s", urlStr)
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return nil
+	}
+
+	rules, err := r.site(ctx, u)
+	if err != nil {
+		return err
+	}
+
+	if !rules.allowed(u) {
+		return fmt.Errorf("%w: %s", ErrDisallowed, urlStr)
+	}
+	return nil
+}
+
+// site returns the cached rules for u's site, fetching them if needed.
+func (r *Robots) site(ctx context.Context, u *url.URL) (*robotsRules, error) {
+	key := u.Scheme + "://" + u.Host
+
+	r.mu.Lock()
+	rules, ok := r.sites[key]
+	r.mu.Unlock()
+	if ok {
+		return rules, nil
+	}
+
+	rules, err := r.fetch(ctx, key+"/robots.txt")
+	if err != nil {
+		return nil, err
+	}
+
+	r.mu.Lock()
+	if r.sites == nil {
+		r.sites = make(map[string]*robotsRules)
+	}
+	r.sites[key] = rules
+	r.mu.Unlock()
+
+	return rules, nil
+}
+
+// fetch downloads and parses a robots.txt file. A missing file allows
+// everything; a server error disallows everything, as RFC 9309 asks.
+func (r *Robots) fetch(ctx context.Context, robotsURL string) (*robotsRules, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", robotsURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	if r.UserAgent != "" {
+		req.Header.Set("User-Agent", r.UserAgent)
+	}
+
+	client := r.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch robots.txt: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 500 {
+		return &robotsRules{disallowAll: true}, nil
+	}
+	if resp.StatusCode != http.StatusOK {
+		return &robotsRules{}, nil
+	}
+
+	agent := r.Agent
+	if agent == "" {
+		agent = DefaultRobotsAgent
+	}
+
+	return parseRobots(io.LimitReader(resp.Body, maxRobotsSize), agent), nil
+}
+
+// parseRobots reads the rules that apply to agent: those of the groups
+// naming it, or else those of the "*" groups.
+func parseRobots(r io.Reader, agent string) *robotsRules {
+	agent = strings.ToLower(agent)
+
+	var (
+		specific, wildcard []robotsRule
+		hasSpecific        bool
+		groupAgents        []string
+		inRules            bool
+	)
+
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if i := strings.Index(line, "#"); i >= 0 {
+			line = line[:i]
+		}
+
+		key, value, ok := strings.Cut(line, ":")
+		if !ok {
+			continue
+		}
+		key = strings.ToLower(strings.TrimSpace(key))
+		value = strings.TrimSpace(value)
+
+		switch key {
+		case "user-agent":
+			// A User-agent line after rules starts a new group
+			if inRules {
+				groupAgents = nil
+				inRules = false
+			}
+			groupAgent := strings.ToLower(value)
+			groupAgents = append(groupAgents, groupAgent)
+			// A group for our agent applies even if it allows everything
+			// with an empty Disallow
+			if groupAgent == agent {
+				hasSpecific = true
+			}
+		case "allow", "disallow":
+			inRules = true
+			if value == "" {
+				continue
+			}
+			rule := robotsRule{allow: key == "allow", pattern: value}
+			for _, groupAgent := range groupAgents {
+				switch groupAgent {
+				case agent:
+					specific = append(specific, rule)
+				case "*":
+					wildcard = append(wildcard, rule)
+				}
+			}
+		}
+	}
+
+	if hasSpecific {
+		return &robotsRules{rules: specific}
+	}
+	return &robotsRules{rules: wildcard}
+}
+
+// allowed applies the most specific matching rule, preferring Allow on a
+// tie. /robots.txt itself is always allowed.
+func (r *robotsRules) allowed(u *url.URL) bool {
+	path := u.EscapedPath()
+	if path == "" {
+		path = "/"
+	}
+	if path == "/robots.txt" {
+		return true
+	}
+	if r.disallowAll {
+		return false
+	}
+	if u.RawQuery != "" {
+		path += "?" + u.RawQuery
+	}
+
+	allow, length := true, -1
+	for _, rule := range r.rules {
+		if !robotsMatch(rule.pattern, path) {
+			continue
+		}
+		if len(rule.pattern) > length || (len(rule.pattern) == length && rule.allow) {
+			allow, length = rule.allow, len(rule.pattern)
+		}
+	}
+	return allow
+}
+
+// robotsMatch matches a path against a robots.txt pattern, in which "*"
+// matches any sequence of characters and a trailing "$" anchors the end.
+func robotsMatch(pattern, path string) bool {
+	anchored := strings.HasSuffix(pattern, "$")
+	pattern = strings.TrimSuffix(pattern, "$")
+
+	parts := strings.Split(pattern, "*")
+	if !strings.HasPrefix(path, parts[0]) {
+		return false
+	}
+	rest := path[len(parts[0]):]
+
+	for i, part := range parts[1:] {
+		last := i == len(parts)-2
+		if last && anchored {
+			return strings.HasSuffix(rest, part)
+		}
+		j := strings.Index(rest, part)
+		if j < 0 {
+			return false
+		}
+		rest = rest[j+len(part):]
+	}
+
+	return !anchored || rest == ""
+}
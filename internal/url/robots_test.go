@@ -0,0 +1,167 @@
+package url
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+func TestParseRobots(t *testing.T) {
+	tests := []struct {
+		name    string
+		robots  string
+		path    string
+		allowed bool
+	}{
+		{"no rules", "", "/page", true},
+		{"wildcard disallow", "User-agent: *\nDisallow: /private/", "/private/page", false},
+		{"wildcard other path", "User-agent: *\nDisallow: /private/", "/public/page", true},
+		{
+			// A group for our agent that allows everything overrides "*"
+			name:    "empty disallow for agent",
+			robots:  "User-agent: bibapa\nDisallow:\n\nUser-agent: *\nDisallow: /",
+			path:    "/page",
+			allowed: true,
+		},
+		{
+			name:    "agent group replaces wildcard",
+			robots:  "User-agent: *\nDisallow: /a\n\nUser-agent: BibAPA\nDisallow: /b",
+			path:    "/a",
+			allowed: true,
+		},
+		{
+			name:    "agent group rules apply",
+			robots:  "User-agent: *\nDisallow: /a\n\nUser-agent: BibAPA\nDisallow: /b",
+			path:    "/b/page",
+			allowed: false,
+		},
+		{
+			name:    "other agent ignored",
+			robots:  "User-agent: googlebot\nDisallow: /",
+			path:    "/page",
+			allowed: true,
+		},
+		{
+			name:    "grouped user agents",
+			robots:  "User-agent: googlebot\nUser-agent: bibapa\nDisallow: /search",
+			path:    "/search?q=x",
+			allowed: false,
+		},
+		{
+			name:    "longest match wins",
+			robots:  "User-agent: *\nDisallow: /docs/\nAllow: /docs/public/",
+			path:    "/docs/public/page",
+			allowed: true,
+		},
+		{
+			name:    "allow wins a tie",
+			robots:  "User-agent: *\nDisallow: /page\nAllow: /page",
+			path:    "/page",
+			allowed: true,
+		},
+		{
+			name:    "comments",
+			robots:  "# rules\nUser-agent: * # everyone\nDisallow: /tmp # scratch",
+			path:    "/tmp/file",
+			allowed: false,
+		},
+		{"robots.txt always allowed", "User-agent: *\nDisallow: /", "/robots.txt", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rules := parseRobots(strings.NewReader(tt.robots), DefaultRobotsAgent)
+			u, err := url.Parse("https://example.com" + tt.path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := rules.allowed(u); got != tt.allowed {
+				t.Errorf("allowed(%s) = %v, want %v", tt.path, got, tt.allowed)
+			}
+		})
+	}
+}
+
+func TestRobotsMatch(t *testing.T) {
+	tests := []struct {
+		pattern string
+		path    string
+		want    bool
+	}{
+		{"/", "/anything", true},
+		{"/fish", "/fish.html", true},
+		{"/fish", "/Fish.html", false},
+		{"/fish/", "/fish", false},
+		{"/*.php", "/index.php", true},
+		{"/*.php", "/folder/index.php?x=1", true},
+		{"/*.php$", "/index.php", true},
+		{"/*.php$", "/index.php?x=1", false},
+		{"/fish*", "/fishheads", true},
+		{"/a*b*c", "/a-b-c", true},
+		{"/a*b*c", "/a-c-b", false},
+		{"/page$", "/page", true},
+		{"/page$", "/pages", false},
+	}
+
+	for _, tt := range tests {
+		if got := robotsMatch(tt.pattern, tt.path); got != tt.want {
+			t.Errorf("robotsMatch(%q, %q) = %v, want %v", tt.pattern, tt.path, got, tt.want)
+		}
+	}
+}
+
+func TestRobotsCheck(t *testing.T) {
+	var (
+		fetches    int
+		userAgents []string
+		status     = http.StatusOK
+	)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/robots.txt" {
+			w.Write([]byte("<html></html>"))
+			return
+		}
+		fetches++
+		userAgents = append(userAgents, r.Header.Get("User-Agent"))
+		if status != http.StatusOK {
+			w.WriteHeader(status)
+			return
+		}
+		w.Write([]byte("User-agent: *\nDisallow: /private/\n"))
+	}))
+	defer server.Close()
+
+	robots := NewRobots(server.Client())
+	robots.UserAgent = "test-agent"
+
+	if err := robots.Check(context.Background(), server.URL+"/public"); err != nil {
+		t.Errorf("Check(/public) error = %v", err)
+	}
+	if err := robots.Check(context.Background(), server.URL+"/private/page"); !errors.Is(err, ErrDisallowed) {
+		t.Errorf("Check(/private/page) error = %v, want ErrDisallowed", err)
+	}
+	if fetches != 1 {
+		t.Errorf("robots.txt fetched %d times, want 1", fetches)
+	}
+	if len(userAgents) != 1 || userAgents[0] != "test-agent" {
+		t.Errorf("User-Agent = %v, want test-agent", userAgents)
+	}
+
+	// A missing robots.txt allows everything, a server error nothing
+	status = http.StatusNotFound
+	if err := NewRobots(server.Client()).Check(context.Background(), server.URL+"/private/page"); err != nil {
+		t.Errorf("Check() with no robots.txt error = %v", err)
+	}
+	status = http.StatusServiceUnavailable
+	if err := NewRobots(server.Client()).Check(context.Background(), server.URL+"/public"); !errors.Is(err, ErrDisallowed) {
+		t.Errorf("Check() with robots.txt unavailable error = %v, want ErrDisallowed", err)
+	}
+
+	if err := robots.Check(context.Background(), "not a url"); err == nil {
+		t.Errorf("Check() of an invalid URL succeeded")
+	}
+}
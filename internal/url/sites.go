@@ -0,0 +1,262 @@
+package url
+
+import (
+	"net/url"
+	"regexp"
+	"strings"
+	"sync"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+// SiteExtractor refines the metadata of a page on a known site. It runs after
+// the generic meta tag and structured data extraction, and may override any
+// field.
+type SiteExtractor func(doc *goquery.Document, u *url.URL, metadata *Metadata)
+
+type site struct {
+	pattern string
+	extract SiteExtractor
+}
+
+var (
+	sitesMu sync.RWMutex
+	sites   []site
+)
+
+// RegisterSite adds an extractor for pages whose hostname matches pattern. A
+// pattern is a domain such as "wikipedia.org" and also matches its
+// subdomains. Extractors registered later take precedence over earlier ones.
+func RegisterSite(pattern string, extract SiteExtractor) {
+	sitesMu.Lock()
+	defer sitesMu.Unlock()
+
+	pattern = strings.TrimPrefix(strings.ToLower(pattern), "*.")
+	sites = append([]site{{pattern: pattern, extract: extract}}, sites...)
+}
+
+// siteExtractor returns the extractor registered for host, if any.
+func siteExtractor(host string) SiteExtractor {
+	sitesMu.RLock()
+	defer sitesMu.RUnlock()
+
+	host = strings.TrimSuffix(strings.ToLower(host), ".")
+	for _, s := range sites {
+		if host == s.pattern || strings.HasSuffix(host, "."+s.pattern) {
+			return s.extract
+		}
+	}
+
+	return nil
+}
+
+// applySiteExtractor runs the extractor for the page's site, if there is one.
+func applySiteExtractor(doc *goquery.Document, urlStr string, metadata *Metadata) {
+	u, err := url.Parse(urlStr)
+	if err != nil || u.Hostname() == "" {
+		return
+	}
+
+	if extract := siteExtractor(u.Hostname()); extract != nil {
+		extract(doc, u, metadata)
+	}
+}
+
+// newsSites maps news outlets to the name they are cited under.
+var newsSites = []struct {
+	host string
+	name string
+}{
+	{"nytimes.com", "The New York Times"},
+	{"washingtonpost.com", "The Washington Post"},
+	{"wsj.com", "The Wall Street Journal"},
+	{"latimes.com", "Los Angeles Times"},
+	{"usatoday.com", "USA Today"},
+	{"theguardian.com", "The Guardian"},
+	{"ft.com", "Financial Times"},
+	{"economist.com", "The Economist"},
+	{"bbc.com", "BBC News"},
+	{"bbc.co.uk", "BBC News"},
+	{"reuters.com", "Reuters"},
+	{"apnews.com", "Associated Press"},
+	{"bloomberg.com", "Bloomberg"},
+	{"cnn.com", "CNN"},
+	{"npr.org", "NPR"},
+	{"aljazeera.com", "Al Jazeera"},
+}
+
+func init() {
+	for _, news := range newsSites {
+		RegisterSite(news.host, newsExtractor(news.name))
+	}
+	RegisterSite("wikipedia.org", extractWikipedia)
+	RegisterSite("github.com", extractGitHub)
+	RegisterSite("youtube.com", extractYouTube)
+	RegisterSite("youtu.be", extractYouTube)
+	RegisterSite("twitter.com", socialExtractor("Twitter", tweetAccount))
+	RegisterSite("x.com", socialExtractor("X", tweetAccount))
+	RegisterSite("facebook.com", socialExtractor("Facebook", facebookAccount))
+}
+
+// wikipediaSuffix matches the site name appended to Wikipedia page titles in
+// every language edition, e.g. " - Wikipedia" or " – Wikipedia".
+var wikipediaSuffix = regexp.MustCompile(`\s+[-–—]\s+Wikipedia.*$`)
+
+// extractWikipedia cites an article as a wiki entry. Wiki pages have no
+// author and change continually, so they are undated.
+func extractWikipedia(doc *goquery.Document, u *url.URL, metadata *Metadata) {
+	metadata.Type = TypeWiki
+	metadata.Authors = nil
+	metadata.Date = Date{}
+	metadata.Publisher = "Wikipedia"
+
+	// Articles are cited without an author or date, so their absence is
+	// not a gap to fill in
+	for _, field := range []string{FieldAuthors, FieldDate, FieldPublisher} {
+		metadata.setSource(field, SourceSite)
+	}
+
+	if heading := strings.TrimSpace(doc.Find("#firstHeading").First().Text()); heading != "" {
+		metadata.Title = heading
+	} else {
+		metadata.Title = wikipediaSuffix.ReplaceAllString(metadata.Title, "")
+	}
+}
+
+// githubReserved lists top-level GitHub paths that are not user accounts.
+var githubReserved = map[string]bool{
+	"about": true, "collections": true, "enterprise": true, "explore": true,
+	"features": true, "login": true, "marketplace": true, "orgs": true,
+	"pricing": true, "settings": true, "sponsors": true, "topics": true,
+}
+
+// extractGitHub cites a repository as software, with its owner as the
+// group author and the repository name as the title.
+func extractGitHub(doc *goquery.Document, u *url.URL, metadata *Metadata) {
+	parts := strings.Split(strings.Trim(u.Path, "/"), "/")
+	if len(parts) < 2 || parts[0] == "" || githubReserved[parts[0]] {
+		return
+	}
+
+	metadata.Type = TypeSoftware
+	metadata.Authors = nil
+	metadata.Publisher = parts[0]
+	metadata.Title = parts[1]
+	metadata.URL = "https://github.com/" + parts[0] + "/" + parts[1]
+
+	// The owner is the author
+	metadata.setSource(FieldAuthors, SourceSite)
+}
+
+// extractYouTube cites a video with its channel as the author.
+func extractYouTube(doc *goquery.Document, u *url.URL, metadata *Metadata) {
+	isVideo := u.Query().Get("v") != "" || u.Hostname() == "youtu.be" || strings.HasPrefix(u.Path, "/shorts/")
+	if !isVideo {
+		return
+	}
+
+	metadata.Type = TypeVideo
+
+	if title := metaContent(doc, `meta[name="title"]`); title != "" {
+		metadata.Title = title
+	}
+
+	channel := doc.Find(`span[itemprop="author"] link[itemprop="name"]`).First().AttrOr("content", "")
+	if channel = strings.TrimSpace(channel); channel != "" {
+		metadata.Authors = nil
+		metadata.Publisher = channel
+	}
+
+	for _, selector := range []string{`meta[itemprop="datePublished"]`, `meta[itemprop="uploadDate"]`} {
+		if date := ParseDate(metaContent(doc, selector)); !date.IsZero() {
+			metadata.Date = date
+			break
+		}
+	}
+}
+
+// tweetAccount returns the handle in a tweet URL, /{handle}/status/{id}.
+func tweetAccount(u *url.URL) string {
+	parts := strings.Split(strings.Trim(u.Path, "/"), "/")
+	if len(parts) < 3 || parts[1] != "status" {
+		return ""
+	}
+	return parts[0]
+}
+
+// facebookAccount returns the account in the URL of a Facebook post, either
+// /{account}/posts/{id} or /permalink.php?story_fbid={id}&id={account}.
+func facebookAccount(u *url.URL) string {
+	parts := strings.Split(strings.Trim(u.Path, "/"), "/")
+	if len(parts) >= 3 && parts[1] == "posts" {
+		return parts[0]
+	}
+	if parts[0] == "permalink.php" {
+		return u.Query().Get("id")
+	}
+	return ""
+}
+
+// socialExtractor returns an extractor that cites posts on a social network
+// by their text, with the account's name as the author and its handle as
+// the screen name. account returns the handle in a post URL, or "" for
+// other pages.
+func socialExtractor(platform string, account func(*url.URL) string) SiteExtractor {
+	return func(doc *goquery.Document, u *url.URL, metadata *Metadata) {
+		handle := account(u)
+		if handle == "" {
+			return
+		}
+
+		metadata.Type = TypeSocialPost
+		metadata.Publisher = platform
+		metadata.ScreenName = handle
+
+		// The post's text is its title
+		if text := metaContent(doc, `meta[property="og:description"]`); text != "" {
+			metadata.Title = strings.Trim(text, "“”\" ")
+		}
+
+		// og:title reads "Name on X" or "Name (@handle) on X"
+		name := metaContent(doc, `meta[property="og:title"]`)
+		if i := strings.LastIndex(name, " on "); i > 0 {
+			name = name[:i]
+		}
+		if i := strings.Index(name, " (@"); i > 0 {
+			name = name[:i]
+		}
+		// Without a name, the post is cited under the handle alone
+		metadata.Authors = ParseByline(name)
+
+		for _, field := range []string{FieldTitle, FieldAuthors, FieldPublisher} {
+			metadata.setSource(field, SourceSite)
+		}
+	}
+}
+
+// newsExtractor returns an extractor that cites articles from a news outlet
+// as newspaper articles under the outlet's usual name.
+func newsExtractor(name string) SiteExtractor {
+	return func(doc *goquery.Document, u *url.URL, metadata *Metadata) {
+		// Section fronts and the home page are ordinary web pages
+		if metadata.Type != TypeArticle && metadata.Type != TypeNewsArticle && metaContent(doc, `meta[property="article:published_time"]`) == "" {
+			return
+		}
+
+		metadata.Type = TypeNewsArticle
+		metadata.Journal = name
+		metadata.Publisher = name
+
+		// Profile links in article:author are skipped, leaving no authors;
+		// the byline is the next best source
+		if len(metadata.Authors) == 0 {
+			metadata.Authors = ParseByline(metaContent(doc, `meta[name="byl"]`))
+		}
+	}
+}
+
+// metaContent returns the trimmed content attribute of the first element
+// matching selector.
+func metaContent(doc *goquery.Document, selector string) string {
+	return strings.TrimSpace(doc.Find(selector).First().AttrOr("content", ""))
+}
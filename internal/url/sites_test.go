@@ -0,0 +1,148 @@
+package url
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/knhn1004/bibtext-to-apa6/internal/bibtex"
+)
+
+func TestSiteExtractors(t *testing.T) {
+	tests := []struct {
+		fixture    string
+		url        string
+		wantType   string
+		wantTitle  string
+		wantAuthor []bibtex.Name
+		publisher  string
+		journal    string
+		date       string
+		screenName string
+		wantURL    string
+	}{
+		{
+			// Wiki articles are cited without an author or date
+			fixture:   "wikipedia",
+			url:       "https://en.wikipedia.org/wiki/Braided_river",
+			wantType:  TypeWiki,
+			wantTitle: "Braided river",
+			publisher: "Wikipedia",
+		},
+		{
+			fixture:   "github",
+			url:       "https://github.com/knhn1004/bibtext-to-apa6/tree/main/internal",
+			wantType:  TypeSoftware,
+			wantTitle: "bibtext-to-apa6",
+			publisher: "knhn1004",
+			wantURL:   "https://github.com/knhn1004/bibtext-to-apa6",
+		},
+		{
+			fixture:   "github-topic",
+			url:       "https://github.com/topics/go",
+			wantType:  TypeWebPage,
+			wantTitle: "go · GitHub Topics",
+			publisher: "GitHub",
+		},
+		{
+			fixture:   "youtube",
+			url:       "https://www.youtube.com/watch?v=abc123XYZ",
+			wantType:  TypeVideo,
+			wantTitle: "How Rivers Shape the Land",
+			publisher: "TEDx Talks",
+			date:      "2014-10-21",
+		},
+		{
+			fixture:    "tweet",
+			url:        "https://x.com/BarackObama/status/297783563935080448",
+			wantType:   TypeSocialPost,
+			wantTitle:  "Those who oppose the plan to fix our immigration system are doing so for no good reason.",
+			wantAuthor: []bibtex.Name{{Family: "Obama", Given: "Barack"}},
+			publisher:  "X",
+			screenName: "BarackObama",
+		},
+		{
+			fixture:    "facebook",
+			url:        "https://www.facebook.com/nih.gov/posts/10153115233411951",
+			wantType:   TypeSocialPost,
+			wantTitle:  "Are you a tech-savvy science teacher? Share your ideas with us",
+			wantAuthor: []bibtex.Name{{Family: "National Institutes of Health", Corporate: true}},
+			publisher:  "Facebook",
+			screenName: "nih.gov",
+		},
+		{
+			// Typed by JSON-LD, without an article:published_time tag
+			fixture:    "nytimes-article",
+			url:        "https://www.nytimes.com/2023/03/02/us/floods.html",
+			wantType:   TypeNewsArticle,
+			wantTitle:  "Floods Reshape Farmland Along the River",
+			wantAuthor: []bibtex.Name{{Family: "Hendrix", Given: "Steve"}},
+			publisher:  "The New York Times",
+			journal:    "The New York Times",
+			date:       "2023-03-02",
+		},
+		{
+			// Section fronts are ordinary web pages
+			fixture:   "nytimes-section",
+			url:       "https://www.nytimes.com/section/science",
+			wantType:  TypeWebPage,
+			wantTitle: "Science",
+			publisher: "Nytimes",
+		},
+		{
+			// A profile link in article:author is not a byline
+			fixture:   "bbc-article",
+			url:       "https://www.bbc.co.uk/news/world-europe-66468911",
+			wantType:  TypeNewsArticle,
+			wantTitle: "Drought leaves barges stranded on the Rhine",
+			publisher: "BBC News",
+			journal:   "BBC News",
+			date:      "2023-08-11",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.fixture, func(t *testing.T) {
+			f, err := os.Open(filepath.Join("testdata", "sites", tt.fixture+".html"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer f.Close()
+
+			metadata, err := ParseHTML(f, tt.url)
+			if err != nil {
+				t.Fatalf("ParseHTML() error = %v", err)
+			}
+
+			if metadata.Type != tt.wantType {
+				t.Errorf("Type = %q, want %q", metadata.Type, tt.wantType)
+			}
+			if metadata.Title != tt.wantTitle {
+				t.Errorf("Title = %q, want %q", metadata.Title, tt.wantTitle)
+			}
+			if (len(metadata.Authors) > 0 || len(tt.wantAuthor) > 0) && !reflect.DeepEqual(metadata.Authors, tt.wantAuthor) {
+				t.Errorf("Authors = %v, want %v", metadata.Authors, tt.wantAuthor)
+			}
+			if metadata.Publisher != tt.publisher {
+				t.Errorf("Publisher = %q, want %q", metadata.Publisher, tt.publisher)
+			}
+			if metadata.Journal != tt.journal {
+				t.Errorf("Journal = %q, want %q", metadata.Journal, tt.journal)
+			}
+			if date := metadata.Date.String(); date != tt.date {
+				t.Errorf("Date = %q, want %q", date, tt.date)
+			}
+			if metadata.ScreenName != tt.screenName {
+				t.Errorf("ScreenName = %q, want %q", metadata.ScreenName, tt.screenName)
+			}
+			wantURL := tt.wantURL
+			if wantURL == "" {
+				wantURL = tt.url
+			}
+			if metadata.URL != wantURL {
+				t.Errorf("URL = %q, want %q", metadata.URL, wantURL)
+			}
+		})
+	}
+}